@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"mirorim-cli/internal/config"
 	"mirorim-cli/internal/dotenv"
 	"mirorim-cli/internal/ui"
+	"mirorim-cli/internal/utils"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -22,7 +25,8 @@ var envCmd = &cobra.Command{
 var envInitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize the environment configuration for the project",
-	Long:  `Initializes the .env file, env.d.ts (if necessary), and sets up dotenv support for the project.`,
+	Long: `Initializes the .env file, the typed declarations (env.d.ts for Bare React Native, src/config/env.ts for Expo), and sets up dotenv support for the project.
+In Bare React Native projects, the react-native-dotenv plugin options can be set with the same flags as 'env configure'.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get current project path
 		projectPath, err := os.Getwd()
@@ -49,19 +53,185 @@ var envInitCmd = &cobra.Command{
 			return
 		}
 
+		// Store the react-native-dotenv options before the plugin is added
+		plugin, changed, err := dotenvPluginFromFlags(cmd, projectConfig.DotenvPlugin)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if changed && projectConfig.ProjectType != "bare" {
+			fmt.Println("Note: the react-native-dotenv options only apply to Bare React Native projects.")
+		} else if changed {
+			err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+				cfg.DotenvPlugin = plugin
+			})
+			if err != nil {
+				fmt.Printf("Error saving project config: %v\n", err)
+				return
+			}
+		}
+
 		// Initialize the environment configuration
 		err = dotenv.CreateEnvFiles(projectPath, projectConfig.ProjectType)
 		if err != nil {
 			fmt.Printf("Error initializing environment configuration: %v\n", err)
 			return
 		}
+
+		// Also create the file of the environment selected with --env
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.LoadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error creating %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		// Bootstrap an empty environment file from its committed encrypted counterpart
+		encPath := dotenv.EncryptedFilePath(projectPath, envName)
+		if _, err := os.Stat(encPath); err == nil && len(envFile.ListKeys()) == 0 {
+			err = decryptEnvironment(cmd, projectPath, envName)
+			if err != nil {
+				fmt.Printf("Warning: could not decrypt %s: %v\n", filepath.Base(encPath), err)
+				return
+			}
+
+			err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+			if err != nil {
+				fmt.Printf("Error updating type declarations: %v\n", err)
+				return
+			}
+			fmt.Printf("Bootstrapped %s from %s.\n", dotenv.EnvFileName(envName), filepath.Base(encPath))
+		}
 	},
 }
 
+// envUseCmd switches the active environment
+var envUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch the active environment",
+	Long: `Records <name> as the active environment in the project configuration and
+materializes .env.<name> as .env, which is the file read by react-native-dotenv and Expo.
+Later env commands without --env work on .env.<name> and keep .env in sync.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		envName := args[0]
+		if err := utils.ValidateEnvName(envName); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Ensure the environment has been initialized
+		envInitialized, err := dotenv.CheckEnvInitialized(projectPath)
+		if err != nil {
+			fmt.Printf("Error checking env initialization: %v\n", err)
+			return
+		}
+		if !envInitialized {
+			fmt.Println("Environment is not initialized. Run 'env init' first.")
+			return
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		err = dotenv.UseEnvironment(projectPath, envName, force)
+		if err != nil {
+			fmt.Printf("Error switching environment: %v\n", err)
+			return
+		}
+
+		// The new .env may declare different keys
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Active environment is now %s.\n", envName)
+	},
+}
+
+// envListEnvsCmd lists the environment files of the project
+var envListEnvsCmd = &cobra.Command{
+	Use:   "list-envs",
+	Short: "List environment files and the keys each one is missing",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		environments, err := dotenv.ListEnvironments(projectPath)
+		if err != nil {
+			fmt.Printf("Error listing environments: %v\n", err)
+			return
+		}
+
+		for _, env := range environments {
+			marker := " "
+			if env.Active {
+				marker = "*"
+			}
+
+			if !env.Exists {
+				fmt.Printf("%s %-14s %-20s not found\n", marker, env.Name, dotenv.EnvFileName(env.Name))
+				continue
+			}
+
+			fmt.Printf("%s %-14s %-20s %d keys", marker, env.Name, dotenv.EnvFileName(env.Name), len(env.Keys))
+			if len(env.MissingKeys) > 0 {
+				fmt.Printf(", missing: %s", strings.Join(env.MissingKeys, ", "))
+			}
+			fmt.Println()
+		}
+	},
+}
+
+// resolveEnvName returns the environment selected with --env, falling back to
+// the active environment recorded in the project config ("" means .env)
+func resolveEnvName(cmd *cobra.Command, projectConfig *config.ProjectConfig) (string, error) {
+	envName, _ := cmd.Flags().GetString("env")
+	if envName == "" {
+		return projectConfig.ActiveEnv, nil
+	}
+	if err := utils.ValidateEnvName(envName); err != nil {
+		return "", err
+	}
+	return envName, nil
+}
+
+// syncActiveEnv rematerializes .env after the file of the active environment changed
+func syncActiveEnv(projectPath, envName string, projectConfig *config.ProjectConfig) error {
+	if envName == "" || envName != projectConfig.ActiveEnv {
+		return nil
+	}
+	return dotenv.MaterializeEnvironment(projectPath, envName)
+}
+
 // envAddCmd represents adding new environment variables
 var envAddCmd = &cobra.Command{
-	Use:   "add",
+	Use:   "add [KEY=VALUE...]",
 	Short: "Add a new environment variable",
+	Long: `Adds one or more environment variables. Variables can be given as KEY=VALUE
+arguments or with --key and --value (or --from-stdin to read the value from standard input).
+Without any of these, you are prompted for the key and value when a terminal is attached.
+Overwriting an existing value asks for confirmation unless --yes is passed.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get current project path
 		projectPath, err := os.Getwd()
@@ -88,40 +258,77 @@ var envAddCmd = &cobra.Command{
 			return
 		}
 
-		// Prompt for new key-value pair
-		key, value, err := ui.PromptEnvKeyValue("add")
+		// Read key-value pairs from arguments, flags or prompts
+		assignments, err := readAssignments(cmd, args, func() (string, string, error) {
+			return ui.PromptEnvKeyValue("add")
+		})
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		// Handle Expo-specific logic
-		if projectConfig.ProjectType == "expo" {
-			key = dotenv.EnsureExpoPrefix(key) // Ensure Expo keys have EXPO_PUBLIC_ prefix
-		}
-
 		// Handle Expo or Bare
-		envFilePath := filepath.Join(projectPath, ".env")
-		envFile, err := dotenv.LoadEnvFile(envFilePath)
+		envName, err := resolveEnvName(cmd, projectConfig)
 		if err != nil {
-			fmt.Printf("Error loading .env file: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		envFile.AddOrUpdateKey(key, value)
-
-		if projectConfig.ProjectType == "bare" {
-			err = dotenv.UpdateEnvDTS(projectPath, key, value, false)
+		// Keep the keys marked build-time-only or server-only out of the app bundle
+		schema, err := loadOrCreateSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		keys := make([]string, len(assignments))
+		for i, assignment := range assignments {
+			keys[i], err = exposedKey(assignment.Key, projectConfig, schema)
 			if err != nil {
-				fmt.Printf("Error updating env.d.ts: %v\n", err)
+				fmt.Printf("Error: %v\n", err)
 				return
 			}
 		}
 
-		// Save changes to .env file
-		err = envFile.SaveEnvFile()
+		// Ask before replacing existing values unless --yes was given
+		envFile, err := dotenv.LoadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+		confirmed, err := confirmOverwrite(cmd, envFile, keys, assignments)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return
+		}
+
+		// Place new keys in the requested section, or at the end of the file
+		section, _ := cmd.Flags().GetString("section")
+		err = dotenv.UpdateEnvFile(envFile.Path, func(envFile *dotenv.DotenvFile) error {
+			for i, assignment := range assignments {
+				envFile.AddOrUpdateKeyInSection(keys[i], assignment.Value, section)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error saving %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		// Keep .env in sync when the active environment changed
+		err = syncActiveEnv(projectPath, envName, projectConfig)
 		if err != nil {
-			fmt.Printf("Error saving .env file: %v\n", err)
+			fmt.Printf("Error updating .env: %v\n", err)
+			return
+		}
+
+		// Regenerate the type declarations from the updated keys
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
 			return
 		}
 
@@ -131,8 +338,12 @@ var envAddCmd = &cobra.Command{
 
 // envUpdateCmd represents updating an existing environment variable
 var envUpdateCmd = &cobra.Command{
-	Use:   "update",
+	Use:   "update [KEY=VALUE...]",
 	Short: "Update an existing environment variable",
+	Long: `Updates one or more existing environment variables. Variables can be given as
+KEY=VALUE arguments or with --key and --value (or --from-stdin to read the value from standard input).
+Without any of these, you are prompted to select a key and enter its value when a terminal is attached.
+Changing a value asks for confirmation unless --yes is passed.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get current project path
 		projectPath, err := os.Getwd()
@@ -159,11 +370,15 @@ var envUpdateCmd = &cobra.Command{
 			return
 		}
 
-		// Load .env file
-		envFilePath := filepath.Join(projectPath, ".env")
-		envFile, err := dotenv.LoadEnvFile(envFilePath)
+		// Load the selected environment file
+		envName, err := resolveEnvName(cmd, projectConfig)
 		if err != nil {
-			fmt.Printf("Error loading .env file: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.LoadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
 			return
 		}
 
@@ -174,37 +389,69 @@ var envUpdateCmd = &cobra.Command{
 			return
 		}
 
-		key, err := ui.PromptSelectKey(existingKeys)
+		assignments, err := readAssignments(cmd, args, func() (string, string, error) {
+			key, err := ui.PromptSelectKey(existingKeys)
+			if err != nil {
+				return "", "", err
+			}
+
+			// Prompt for new value
+			value, err := ui.PromptNewValue()
+			return key, value, err
+		})
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		// Prompt for new value
-		value, err := ui.PromptNewValue()
+		keys := make([]string, len(assignments))
+		for i, assignment := range assignments {
+			keys[i] = definedKey(envFile, assignment.Key, projectConfig)
+			if _, exists := envFile.Variables[keys[i]]; !exists {
+				fmt.Printf("Error: %s is not defined in %s. Use 'env add' to create it.\n", keys[i], dotenv.EnvFileName(envName))
+				return
+			}
+		}
+
+		// Ask before replacing the values unless --yes was given
+		confirmed, err := confirmOverwrite(cmd, envFile, keys, assignments)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
-
-		if projectConfig.ProjectType == "expo" {
-			key = dotenv.EnsureExpoPrefix(key) // Ensure Expo keys have EXPO_PUBLIC_ prefix
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return
 		}
 
-		envFile.AddOrUpdateKey(key, value)
+		// Apply the values to the file as it is now, it may have changed while prompting
+		err = dotenv.UpdateEnvFile(envFile.Path, func(envFile *dotenv.DotenvFile) error {
+			for _, assignment := range assignments {
+				key := definedKey(envFile, assignment.Key, projectConfig)
+				if _, exists := envFile.Variables[key]; !exists {
+					return fmt.Errorf("%s is not defined in %s. Use 'env add' to create it", key, dotenv.EnvFileName(envName))
+				}
 
-		if projectConfig.ProjectType == "bare" {
-			err = dotenv.UpdateEnvDTS(projectPath, key, value, false)
-			if err != nil {
-				fmt.Printf("Error updating env.d.ts: %v\n", err)
-				return
+				envFile.AddOrUpdateKey(key, assignment.Value)
 			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Keep .env in sync when the active environment changed
+		err = syncActiveEnv(projectPath, envName, projectConfig)
+		if err != nil {
+			fmt.Printf("Error updating .env: %v\n", err)
+			return
 		}
 
-		// Save changes to .env file
-		err = envFile.SaveEnvFile()
+		// Regenerate the type declarations from the updated keys
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
 		if err != nil {
-			fmt.Printf("Error saving .env file: %v\n", err)
+			fmt.Printf("Error updating type declarations: %v\n", err)
 			return
 		}
 
@@ -214,8 +461,11 @@ var envUpdateCmd = &cobra.Command{
 
 // envRemoveCmd represents removing an existing environment variable
 var envRemoveCmd = &cobra.Command{
-	Use:   "remove",
+	Use:   "remove [KEY...]",
 	Short: "Remove an existing environment variable",
+	Long: `Removes one or more environment variables given as arguments or with --key.
+Without any of these, you are prompted to select a key when a terminal is attached.
+Removal asks for confirmation unless --yes is passed.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get current project path
 		projectPath, err := os.Getwd()
@@ -242,11 +492,15 @@ var envRemoveCmd = &cobra.Command{
 			return
 		}
 
-		// Load .env file
-		envFilePath := filepath.Join(projectPath, ".env")
-		envFile, err := dotenv.LoadEnvFile(envFilePath)
+		// Load the selected environment file
+		envName, err := resolveEnvName(cmd, projectConfig)
 		if err != nil {
-			fmt.Printf("Error loading .env file: %v\n", err)
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.LoadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
 			return
 		}
 
@@ -257,30 +511,55 @@ var envRemoveCmd = &cobra.Command{
 			return
 		}
 
-		key, err := ui.PromptSelectKey(existingKeys)
+		keys, err := readKeys(cmd, args, func() (string, error) {
+			return ui.PromptSelectKey(existingKeys)
+		})
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
-		if projectConfig.ProjectType == "expo" {
-			key = dotenv.EnsureExpoPrefix(key) // Ensure Expo keys have EXPO_PUBLIC_ prefix
+		for i, key := range keys {
+			keys[i] = definedKey(envFile, key, projectConfig)
+			if _, exists := envFile.Variables[keys[i]]; !exists {
+				fmt.Printf("Error: %s is not defined in %s.\n", keys[i], dotenv.EnvFileName(envName))
+				return
+			}
 		}
 
-		envFile.RemoveKey(key)
+		// Ask before deleting anything unless --yes was given
+		confirmed, err := confirmAction(cmd, fmt.Sprintf("Remove %s from %s?", strings.Join(keys, ", "), dotenv.EnvFileName(envName)))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return
+		}
 
-		if projectConfig.ProjectType == "bare" {
-			err = dotenv.UpdateEnvDTS(projectPath, key, "", true)
-			if err != nil {
-				fmt.Printf("Error updating env.d.ts: %v\n", err)
-				return
+		err = dotenv.UpdateEnvFile(envFile.Path, func(envFile *dotenv.DotenvFile) error {
+			for _, key := range keys {
+				envFile.RemoveKey(key)
 			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error saving %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
 		}
 
-		// Save changes to .env file
-		err = envFile.SaveEnvFile()
+		// Keep .env in sync when the active environment changed
+		err = syncActiveEnv(projectPath, envName, projectConfig)
 		if err != nil {
-			fmt.Printf("Error saving .env file: %v\n", err)
+			fmt.Printf("Error updating .env: %v\n", err)
+			return
+		}
+
+		// Regenerate the type declarations from the updated keys
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
 			return
 		}
 
@@ -292,8 +571,13 @@ var envRemoveCmd = &cobra.Command{
 var envDestroyCmd = &cobra.Command{
 	Use:   "destroy",
 	Short: "Destroy the environment configuration",
-	Long: `Removes the .env and env.d.ts (for Bare React Native) files, 
-and updates the project configuration to mark the environment as uninitialized.`,
+	Long: `Removes the .env file and the generated env.d.ts (Bare React Native) or src/config/env.ts (Expo), 
+and updates the project configuration to mark the environment as uninitialized.
+The changes recorded by 'env init' are undone as well: the babel config is restored to its original
+content, or only loses the react-native-dotenv plugin when it was edited since, and react-native-dotenv
+is uninstalled. The steps are listed first and confirmed unless --yes is passed; --dry-run only lists them.
+With --env, only the .env.<name> file of that environment is removed, together with the .env
+materialized from it when it is the active environment.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get current project path
 		projectPath, err := os.Getwd()
@@ -315,20 +599,265 @@ and updates the project configuration to mark the environment as uninitialized.`
 			return
 		}
 
+		// With --env, only remove that environment's file
+		envName, _ := cmd.Flags().GetString("env")
+		if envName != "" {
+			if err := utils.ValidateEnvName(envName); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+
+		// Show what will be undone before changing anything
+		var plan *dotenv.DestroyPlan
+		if envName != "" {
+			plan, err = dotenv.PlanDestroyEnvironment(projectPath, envName)
+		} else {
+			plan, err = dotenv.PlanDestroy(projectPath, projectConfig.ProjectType)
+		}
+		if err != nil {
+			fmt.Printf("Error planning the destruction: %v\n", err)
+			return
+		}
+		fmt.Println("env destroy will:")
+		for _, step := range plan.Steps {
+			fmt.Printf("  - %s\n", step.Description)
+		}
+		for _, note := range plan.Notes {
+			fmt.Printf("Note: %s\n", note)
+		}
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return
+		}
+
+		message := "Destroy the environment configuration?"
+		if envName != "" {
+			message = fmt.Sprintf("Remove the %s environment?", envName)
+		}
+		confirmed, err := confirmAction(cmd, message)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return
+		}
+
 		// Destroy the environment configuration
-		err = dotenv.DestroyEnvFiles(projectPath, projectConfig.ProjectType)
+		err = plan.Apply()
 		if err != nil {
 			fmt.Printf("Error destroying environment configuration: %v\n", err)
 			return
 		}
+		if envName == "" {
+			fmt.Println("Environment configuration destroyed successfully.")
+			return
+		}
+
+		// The remaining environments still declare their keys
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+		fmt.Printf("Environment %s removed successfully.\n", envName)
 	},
 }
 
 func init() {
+	addSecretsKeyFlags(envInitCmd)
+	addDotenvPluginFlags(envInitCmd)
+	envCmd.PersistentFlags().String("env", "", "Environment to work on, targeting .env.<name> instead of .env")
+	envUseCmd.Flags().Bool("force", false, "Overwrite a .env file that is not managed by an environment")
+	for _, c := range []*cobra.Command{envAddCmd, envUpdateCmd} {
+		c.Flags().String("key", "", "Key of the variable to set")
+		c.Flags().String("value", "", "Value of the variable to set (requires --key)")
+		c.Flags().Bool("from-stdin", false, "Read the value from standard input (requires --key)")
+	}
+	envAddCmd.Flags().BoolP("yes", "y", false, "Overwrite existing values without asking for confirmation")
+	envUpdateCmd.Flags().BoolP("yes", "y", false, "Update without asking for confirmation")
+	envRemoveCmd.Flags().String("key", "", "Key of the variable to remove")
+	envRemoveCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+	envDestroyCmd.Flags().Bool("dry-run", false, "List the changes without making them")
+	envDestroyCmd.Flags().BoolP("yes", "y", false, "Destroy without asking for confirmation")
+	envAddCmd.Flags().String("section", "", "Name of the comment section (e.g. \"API\" for \"# API\") to place a new variable in")
+
 	envCmd.AddCommand(envInitCmd)
 	envCmd.AddCommand(envAddCmd)
 	envCmd.AddCommand(envUpdateCmd)
 	envCmd.AddCommand(envRemoveCmd)
 	envCmd.AddCommand(envDestroyCmd)
+	envCmd.AddCommand(envUseCmd)
+	envCmd.AddCommand(envListEnvsCmd)
 	rootCmd.AddCommand(envCmd)
 }
+
+// normalizeKey upper-cases a key and applies the EXPO_PUBLIC_ prefix for Expo projects
+func normalizeKey(key string, projectConfig *config.ProjectConfig) string {
+	key = strings.ToUpper(key)
+	if projectConfig.ProjectType == "expo" {
+		key = dotenv.EnsureExpoPrefix(key) // Ensure Expo keys have EXPO_PUBLIC_ prefix
+	}
+	return key
+}
+
+// exposedKey returns the key a new variable is stored under, like
+// normalizeKey, for keys the schema may mark as build-time-only or
+// server-only. In Expo projects such keys are kept without the EXPO_PUBLIC_
+// prefix, and refused with it. In bare projects a warning is printed when the
+// react-native-dotenv allowlist or blocklist of the project lets them into the
+// app bundle.
+func exposedKey(key string, projectConfig *config.ProjectConfig, schema *dotenv.Schema) (string, error) {
+	exposure, secret := schema.SecretExposure(strings.ToUpper(key))
+	if !secret {
+		return normalizeKey(key, projectConfig), nil
+	}
+	return secretKey(key, exposure, projectConfig, schema)
+}
+
+// secretKey returns the key a variable with a build-time-only or server-only
+// exposure is stored under, as described for exposedKey
+func secretKey(key, exposure string, projectConfig *config.ProjectConfig, schema *dotenv.Schema) (string, error) {
+	key = strings.ToUpper(key)
+	label := dotenv.ExposureLabel(exposure)
+	switch projectConfig.ProjectType {
+	case "expo":
+		if strings.HasPrefix(key, dotenv.ExpoPublicPrefix) {
+			return "", fmt.Errorf("%s is marked %s, the %s prefix would inline it into the app bundle", key, label, dotenv.ExpoPublicPrefix)
+		}
+		fmt.Printf("Note: %s is marked %s, so it is stored without the %s prefix.\n", key, label, dotenv.ExpoPublicPrefix)
+	case "bare":
+		if !dotenv.BundleProtected(projectConfig.DotenvPlugin, schema, key) {
+			fmt.Printf("Warning: %s is marked %s, but the react-native-dotenv allowlist or blocklist lets it into the app bundle. Run 'env configure --allowlist-public' to keep it out.\n", key, label)
+		}
+	}
+	return key, nil
+}
+
+// keyCandidates returns the keys an existing variable given as key may be
+// defined under: as given, upper-cased and, in Expo projects, with the
+// EXPO_PUBLIC_ prefix, which secret keys are stored without
+func keyCandidates(key string, projectConfig *config.ProjectConfig) []string {
+	candidates := []string{key, strings.ToUpper(key)}
+	if projectConfig.ProjectType == "expo" {
+		candidates = append(candidates, dotenv.EnsureExpoPrefix(strings.ToUpper(key)))
+	}
+	return candidates
+}
+
+// definedKey returns the key a variable of envFile given as key is defined
+// under, or the normalized key when it is not defined
+func definedKey(envFile *dotenv.DotenvFile, key string, projectConfig *config.ProjectConfig) string {
+	for _, candidate := range keyCandidates(key, projectConfig) {
+		if _, exists := envFile.Variables[candidate]; exists {
+			return candidate
+		}
+	}
+	return normalizeKey(key, projectConfig)
+}
+
+// envAssignment is a key-value pair given on the command line or through a prompt
+type envAssignment struct {
+	Key   string
+	Value string
+}
+
+// readAssignments collects the key-value pairs given as KEY=VALUE arguments or
+// with --key/--value/--from-stdin. The prompt is only used as a fallback when
+// nothing was given and a terminal is attached.
+func readAssignments(cmd *cobra.Command, args []string, prompt func() (string, string, error)) ([]envAssignment, error) {
+	var assignments []envAssignment
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid argument %q, expected KEY=VALUE", arg)
+		}
+		if err := utils.ValidateEnvKey(parts[0]); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, envAssignment{Key: parts[0], Value: parts[1]})
+	}
+
+	key, _ := cmd.Flags().GetString("key")
+	value, _ := cmd.Flags().GetString("value")
+	fromStdin, _ := cmd.Flags().GetBool("from-stdin")
+	if key == "" && (cmd.Flags().Changed("value") || fromStdin) {
+		return nil, fmt.Errorf("--value and --from-stdin require --key")
+	}
+	if key != "" {
+		if err := utils.ValidateEnvKey(key); err != nil {
+			return nil, err
+		}
+		if fromStdin {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read value from stdin: %v", err)
+			}
+			value = strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		}
+		assignments = append(assignments, envAssignment{Key: key, Value: value})
+	}
+
+	if len(assignments) > 0 {
+		return assignments, nil
+	}
+
+	if !ui.IsInteractive() {
+		return nil, fmt.Errorf("no variable given; pass KEY=VALUE or --key and --value when no terminal is attached")
+	}
+	key, value, err := prompt()
+	if err != nil {
+		return nil, err
+	}
+	return []envAssignment{{Key: key, Value: value}}, nil
+}
+
+// readKeys collects the keys given as arguments or with --key, falling back to
+// the prompt when nothing was given and a terminal is attached
+func readKeys(cmd *cobra.Command, args []string, prompt func() (string, error)) ([]string, error) {
+	keys := append([]string{}, args...)
+	if key, _ := cmd.Flags().GetString("key"); key != "" {
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 {
+		return keys, nil
+	}
+
+	if !ui.IsInteractive() {
+		return nil, fmt.Errorf("no key given; pass KEY or --key when no terminal is attached")
+	}
+	key, err := prompt()
+	if err != nil {
+		return nil, err
+	}
+	return []string{key}, nil
+}
+
+// confirmOverwrite asks, through confirmAction, before the assignments replace
+// existing values of envFile. keys are the resolved keys of the assignments.
+// Nothing is asked when only new keys are added or the values are unchanged.
+func confirmOverwrite(cmd *cobra.Command, envFile *dotenv.DotenvFile, keys []string, assignments []envAssignment) (bool, error) {
+	var overwritten []string
+	for i, assignment := range assignments {
+		if current, exists := envFile.Variables[keys[i]]; exists && current != assignment.Value {
+			overwritten = append(overwritten, keys[i])
+		}
+	}
+	if len(overwritten) == 0 {
+		return true, nil
+	}
+	return confirmAction(cmd, fmt.Sprintf("Overwrite %s in %s?", strings.Join(overwritten, ", "), filepath.Base(envFile.Path)))
+}
+
+// confirmAction asks the user to confirm a destructive action. --yes skips the
+// question, and without a terminal the action is refused unless --yes is set.
+func confirmAction(cmd *cobra.Command, message string) (bool, error) {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true, nil
+	}
+	if !ui.IsInteractive() {
+		return false, fmt.Errorf("confirmation required; pass --yes when no terminal is attached")
+	}
+	return ui.PromptConfirm(message)
+}
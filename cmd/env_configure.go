@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/utils"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// envConfigureCmd changes the options of the react-native-dotenv babel plugin
+var envConfigureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "Configure the react-native-dotenv babel plugin",
+	Long: `Sets the options of the react-native-dotenv babel plugin in Bare React Native projects: the module the
+variables are imported from (--module-name, @env by default), the env file it loads (--path), safe mode (--safe)
+and the keys it exposes (--allowlist) or hides (--blocklist). --allowlist-public generates the allowlist from
+the keys not marked build-time-only or server-only in env.schema.json, and keeps it up to date as keys
+change. This is the default once env.schema.json marks a key build-time-only or server-only, unless
+--allowlist or --blocklist is set. The options are stored in the project config and
+written to the existing plugin entry of the babel config in place, keeping its other options. env.d.ts is
+regenerated for the new module name. Without flags, the current options are shown.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+		if projectConfig.ProjectType != "bare" {
+			fmt.Println("Error: react-native-dotenv is only used by Bare React Native projects.")
+			return
+		}
+
+		plugin, changed, err := dotenvPluginFromFlags(cmd, projectConfig.DotenvPlugin)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !changed {
+			schema, err := loadOrCreateSchema(projectPath)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			printDotenvPlugin(plugin, schema)
+			return
+		}
+
+		err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+			cfg.DotenvPlugin = plugin
+		})
+		if err != nil {
+			fmt.Printf("Error saving project config: %v\n", err)
+			return
+		}
+
+		// Only an initialized project has the plugin in its babel config
+		if !projectConfig.EnvInitialized {
+			fmt.Println("Saved the options, they will be used by 'env init'.")
+			return
+		}
+
+		fileName, updated, err := dotenv.ConfigureBabelPlugin(projectPath, plugin)
+		if err != nil {
+			fmt.Printf("Error updating the babel config: %v\n", err)
+			return
+		}
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		if !updated {
+			fmt.Printf("%s is already up to date.\n", fileName)
+			return
+		}
+		fmt.Printf("Updated the react-native-dotenv options in %s.\n", fileName)
+		fmt.Println("Restart Metro with --reset-cache for babel to pick them up.")
+	},
+}
+
+// dotenvPluginFromFlags returns the plugin options with the flags applied on
+// top of current, and whether any flag was set
+func dotenvPluginFromFlags(cmd *cobra.Command, current *config.DotenvPlugin) (*config.DotenvPlugin, bool, error) {
+	plugin := &config.DotenvPlugin{}
+	if current != nil {
+		*plugin = *current
+	}
+
+	changed := false
+	if cmd.Flags().Changed("module-name") {
+		plugin.ModuleName, _ = cmd.Flags().GetString("module-name")
+		if strings.TrimSpace(plugin.ModuleName) == "" {
+			return nil, false, fmt.Errorf("--module-name cannot be empty")
+		}
+		changed = true
+	}
+	if cmd.Flags().Changed("path") {
+		plugin.Path, _ = cmd.Flags().GetString("path")
+		changed = true
+	}
+	if cmd.Flags().Changed("safe") {
+		plugin.Safe, _ = cmd.Flags().GetBool("safe")
+		changed = true
+	}
+	for _, list := range []struct {
+		flag  string
+		value *[]string
+	}{{"allowlist", &plugin.Allowlist}, {"blocklist", &plugin.Blocklist}} {
+		if !cmd.Flags().Changed(list.flag) {
+			continue
+		}
+		keys, _ := cmd.Flags().GetStringSlice(list.flag)
+		for _, key := range keys {
+			if err := utils.ValidateEnvKey(key); err != nil {
+				return nil, false, fmt.Errorf("--%s: %v", list.flag, err)
+			}
+		}
+		*list.value = keys
+		changed = true
+	}
+	if cmd.Flags().Changed("allowlist") {
+		plugin.GenerateAllowlist = false
+	}
+	if cmd.Flags().Changed("allowlist-public") {
+		if cmd.Flags().Changed("allowlist") {
+			return nil, false, fmt.Errorf("--allowlist and --allowlist-public cannot be used together")
+		}
+		plugin.GenerateAllowlist, _ = cmd.Flags().GetBool("allowlist-public")
+		plugin.Allowlist = nil
+		changed = true
+	}
+
+	if (len(plugin.Allowlist) > 0 || plugin.GenerateAllowlist) && len(plugin.Blocklist) > 0 {
+		return nil, false, fmt.Errorf("an allowlist and a blocklist cannot be used together")
+	}
+	return plugin, changed, nil
+}
+
+// printDotenvPlugin shows the configured plugin options
+func printDotenvPlugin(plugin *config.DotenvPlugin, schema *dotenv.Schema) {
+	if plugin == nil {
+		plugin = &config.DotenvPlugin{}
+	}
+	moduleName, path := plugin.ModuleName, plugin.Path
+	if moduleName == "" {
+		moduleName = dotenv.EnvModuleName
+	}
+	if path == "" {
+		path = ".env (default)"
+	}
+	fmt.Printf("Module name: %s\n", moduleName)
+	fmt.Printf("Path: %s\n", path)
+	fmt.Printf("Safe: %t\n", plugin.Safe)
+	if dotenv.GeneratesAllowlist(plugin, schema) {
+		fmt.Println("Allowlist: generated from the public keys")
+	} else {
+		fmt.Printf("Allowlist: %s\n", formatKeyList(plugin.Allowlist))
+	}
+	fmt.Printf("Blocklist: %s\n", formatKeyList(plugin.Blocklist))
+}
+
+// formatKeyList renders a list of keys for display
+func formatKeyList(keys []string) string {
+	if len(keys) == 0 {
+		return "none"
+	}
+	return strings.Join(keys, ", ")
+}
+
+// addDotenvPluginFlags registers the flags setting the react-native-dotenv options
+func addDotenvPluginFlags(cmd *cobra.Command) {
+	cmd.Flags().String("module-name", "", "Module the variables are imported from (default @env)")
+	cmd.Flags().String("path", "", "Env file loaded by react-native-dotenv (default .env)")
+	cmd.Flags().Bool("safe", false, "Enable the safe mode of react-native-dotenv")
+	cmd.Flags().StringSlice("allowlist", nil, "Only expose these keys to the app")
+	cmd.Flags().StringSlice("blocklist", nil, "Never expose these keys to the app")
+	cmd.Flags().Bool("allowlist-public", false, "Generate the allowlist from the keys not marked build-time-only or server-only")
+}
+
+func init() {
+	addDotenvPluginFlags(envConfigureCmd)
+	envCmd.AddCommand(envConfigureCmd)
+}
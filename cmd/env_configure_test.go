@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"mirorim-cli/internal/config"
+	"strings"
+	"testing"
+)
+
+// configureBabelConfig returns a babel config whose react-native-dotenv entry
+// holds the given option lines
+func configureBabelConfig(options ...string) string {
+	return "module.exports = {\n  presets: ['module:@react-native/babel-preset'],\n  plugins: [\n    ['module:react-native-dotenv', {\n" +
+		"      " + strings.Join(options, ",\n      ") + ",\n    }],\n  ],\n};\n"
+}
+
+func TestEnvConfigureOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		options []string
+		check   func(plugin *config.DotenvPlugin) bool
+	}{
+		{
+			name:    "module name",
+			args:    []string{"--module-name", "@vars"},
+			options: []string{"moduleName: '@vars'", "custom: 1", "safe: false", "allowlist: null", "blocklist: null"},
+			check:   func(p *config.DotenvPlugin) bool { return p.ModuleName == "@vars" },
+		},
+		{
+			name:    "path",
+			args:    []string{"--path", ".env.local"},
+			options: []string{"moduleName: '@env'", "custom: 1", "path: '.env.local'", "safe: false", "allowlist: null", "blocklist: null"},
+			check:   func(p *config.DotenvPlugin) bool { return p.Path == ".env.local" },
+		},
+		{
+			name:    "safe",
+			args:    []string{"--safe"},
+			options: []string{"moduleName: '@env'", "custom: 1", "safe: true", "allowlist: null", "blocklist: null"},
+			check:   func(p *config.DotenvPlugin) bool { return p.Safe },
+		},
+		{
+			name:    "allowlist",
+			args:    []string{"--allowlist", "API_URL"},
+			options: []string{"moduleName: '@env'", "custom: 1", "safe: false", "allowlist: ['API_URL']", "blocklist: null"},
+			check:   func(p *config.DotenvPlugin) bool { return len(p.Allowlist) == 1 && p.Allowlist[0] == "API_URL" },
+		},
+		{
+			name:    "blocklist",
+			args:    []string{"--blocklist", "SERVER_TOKEN"},
+			options: []string{"moduleName: '@env'", "custom: 1", "safe: false", "allowlist: null", "blocklist: ['SERVER_TOKEN']"},
+			check:   func(p *config.DotenvPlugin) bool { return len(p.Blocklist) == 1 && p.Blocklist[0] == "SERVER_TOKEN" },
+		},
+		{
+			name:    "generated allowlist",
+			args:    []string{"--allowlist-public"},
+			options: []string{"moduleName: '@env'", "custom: 1", "safe: false", "allowlist: ['API_URL', 'SERVER_TOKEN']", "blocklist: null"},
+			check:   func(p *config.DotenvPlugin) bool { return p.GenerateAllowlist },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := newTestProject(t, "bare", map[string]string{
+				".env":            "API_URL=1\nSERVER_TOKEN=2\n",
+				"babel.config.js": configureBabelConfig("moduleName: '@env'", "custom: 1"),
+			})
+
+			output := runCLI(t, projectPath, append([]string{"env", "configure"}, tt.args...)...)
+			if got, want := readTestFile(t, projectPath, "babel.config.js"), configureBabelConfig(tt.options...); got != want {
+				t.Errorf("babel.config.js = %q, want %q\n%s", got, want, output)
+			}
+			cfg, err := config.LoadConfig(projectPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.DotenvPlugin == nil || !tt.check(cfg.DotenvPlugin) {
+				t.Errorf("stored options = %+v", cfg.DotenvPlugin)
+			}
+
+			// Nothing changes when the options are set again
+			output = runCLI(t, projectPath, append([]string{"env", "configure"}, tt.args...)...)
+			if !strings.Contains(output, "already up to date") {
+				t.Errorf("configuring again printed %q", output)
+			}
+		})
+	}
+}
+
+func TestEnvConfigureModuleNameUpdatesTypes(t *testing.T) {
+	projectPath := newTestProject(t, "bare", map[string]string{
+		".env":            "API_URL=1\n",
+		"babel.config.js": configureBabelConfig("moduleName: '@env'"),
+	})
+
+	runCLI(t, projectPath, "env", "configure", "--module-name", "@vars")
+	if types := readTestFile(t, projectPath, "env.d.ts"); !strings.Contains(types, `declare module "@vars"`) {
+		t.Errorf("env.d.ts does not declare @vars:\n%s", types)
+	}
+}
+
+func TestEnvConfigureRejectsConflictingLists(t *testing.T) {
+	babel := configureBabelConfig("moduleName: '@env'")
+	projectPath := newTestProject(t, "bare", map[string]string{".env": "API_URL=1\n", "babel.config.js": babel})
+
+	for _, args := range [][]string{
+		{"--allowlist", "API_URL", "--blocklist", "SERVER_TOKEN"},
+		{"--allowlist-public", "--blocklist", "SERVER_TOKEN"},
+		{"--allowlist", "API_URL", "--allowlist-public"},
+		{"--allowlist", "not a key"},
+		{"--module-name", ""},
+	} {
+		output := runCLI(t, projectPath, append([]string{"env", "configure"}, args...)...)
+		if !strings.HasPrefix(output, "Error: ") {
+			t.Errorf("env configure %s printed %q, want an error", strings.Join(args, " "), output)
+		}
+	}
+	if got := readTestFile(t, projectPath, "babel.config.js"); got != babel {
+		t.Errorf("rejected options changed babel.config.js: %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/utils"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// envDiffCmd compares the variables of two environment files
+var envDiffCmd = &cobra.Command{
+	Use:   "diff A B",
+	Short: "Compare the variables of two environment files",
+	Long: `Lists the keys added, removed and changed between two environment files, with references expanded.
+A and B are environment names like staging and production, or paths to env files like .env.
+Values are masked unless --reveal is set, and --json prints the differences as JSON.
+In Expo projects, keys are compared with the EXPO_PUBLIC_ prefix applied, except for the ones
+env.schema.json marks as build-time-only or server-only.
+Exits with status 1 when the files differ and 2 on errors.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(2)
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+			os.Exit(2)
+		}
+
+		// Load and resolve both files
+		var vars [2]map[string]string
+		for i, arg := range args {
+			path, err := diffTargetPath(projectPath, arg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(2)
+			}
+			envFile, err := dotenv.ReadEnvFile(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", arg, err)
+				os.Exit(2)
+			}
+			vars[i], err = envFile.Resolve(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", arg, err)
+				os.Exit(2)
+			}
+		}
+
+		schema, err := loadOrCreateSchema(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		entries := dotenv.DiffVariables(vars[0], vars[1], projectConfig.ProjectType, schema)
+
+		reveal, _ := cmd.Flags().GetBool("reveal")
+		if !reveal {
+			for i := range entries {
+				entries[i].OldValue = dotenv.MaskValue(entries[i].OldValue)
+				entries[i].NewValue = dotenv.MaskValue(entries[i].NewValue)
+			}
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if entries == nil {
+				entries = []dotenv.DiffEntry{}
+			}
+			data, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error serializing differences: %v\n", err)
+				os.Exit(2)
+			}
+			fmt.Println(string(data))
+		} else if len(entries) == 0 {
+			fmt.Printf("No differences between %s and %s.\n", args[0], args[1])
+		} else {
+			for _, entry := range entries {
+				switch entry.Kind {
+				case dotenv.DiffAdded:
+					fmt.Printf("+ %s=%s\n", entry.Key, entry.NewValue)
+				case dotenv.DiffRemoved:
+					fmt.Printf("- %s=%s\n", entry.Key, entry.OldValue)
+				default:
+					fmt.Printf("~ %s: %s -> %s\n", entry.Key, entry.OldValue, entry.NewValue)
+				}
+			}
+		}
+
+		if len(entries) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// diffTargetPath returns the env file an argument of env diff refers to: an
+// existing file path, or otherwise the file of an environment name
+func diffTargetPath(projectPath, arg string) (string, error) {
+	path := arg
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, arg)
+	}
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return path, nil
+	}
+
+	if err := utils.ValidateEnvName(arg); err != nil {
+		return "", fmt.Errorf("%s is neither an env file nor an environment name", arg)
+	}
+	return dotenv.EnvFilePath(projectPath, arg), nil
+}
+
+func init() {
+	envDiffCmd.Flags().Bool("reveal", false, "Show values instead of masking them")
+	envDiffCmd.Flags().Bool("json", false, "Print the differences as JSON")
+
+	envCmd.AddCommand(envDiffCmd)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// envDoctorCmd checks that the env files of the project cannot leak into git
+var envDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that env files and keys are kept out of git",
+	Long: `Checks that .gitignore ignores the env files of the project, while .env.example and the encrypted
+.env.enc files stay committable, and that no env file, env backup or project key file is tracked by git.
+Use --fix to add the missing patterns to .gitignore. Tracked files are never removed automatically.
+Exits with a non-zero status when a check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			os.Exit(2)
+		}
+
+		if !dotenv.IsGitRepository(projectPath) {
+			fmt.Println("[skip] Not a git repository, nothing to check.")
+			return
+		}
+
+		if fix, _ := cmd.Flags().GetBool("fix"); fix {
+			added, err := dotenv.EnsureGitignore(projectPath)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(2)
+			}
+			if len(added) > 0 {
+				fmt.Printf("Added %s to .gitignore\n", strings.Join(added, ", "))
+			}
+		}
+
+		failed := false
+
+		// Env files must be ignored, the example and encrypted files must not
+		entries, err := os.ReadDir(projectPath)
+		if err != nil {
+			fmt.Printf("Error reading project directory: %v\n", err)
+			os.Exit(2)
+		}
+		names := []string{".env"}
+		for _, entry := range entries {
+			if !entry.IsDir() && entry.Name() != ".env" && strings.HasPrefix(entry.Name(), ".env.") {
+				names = append(names, entry.Name())
+			}
+		}
+
+		var notIgnored, ignored []string
+		for _, name := range names {
+			isIgnored := dotenv.IsGitIgnored(projectPath, name)
+			if dotenv.IsSecretFileName(name) && !isIgnored {
+				notIgnored = append(notIgnored, name)
+			} else if !dotenv.IsSecretFileName(name) && isIgnored {
+				ignored = append(ignored, name)
+			}
+		}
+		if len(notIgnored) > 0 {
+			failed = true
+			fmt.Printf("[fail] Not ignored by .gitignore: %s\n", strings.Join(notIgnored, ", "))
+			fmt.Println("       Run 'mirorim-cli env doctor --fix' to add the env file patterns to .gitignore.")
+		} else {
+			fmt.Println("[ok]   Env files are ignored by .gitignore.")
+		}
+		if len(ignored) > 0 {
+			fmt.Printf("[warn] Meant to be committed but ignored: %s\n", strings.Join(ignored, ", "))
+		}
+
+		// Nothing holding secrets may be in the git index
+		tracked, err := dotenv.TrackedSecretFiles(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		if len(tracked) > 0 {
+			failed = true
+			fmt.Println("[fail] Files holding secrets are tracked by git:")
+			for _, file := range tracked {
+				fmt.Printf("         %s\n", file)
+			}
+			fmt.Println("       Their values are in the git history. Rotate them, then stop tracking the files with:")
+			fmt.Printf("         git rm --cached -- %s\n", strings.Join(tracked, " "))
+		} else {
+			fmt.Println("[ok]   No env file or key file is tracked by git.")
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	envDoctorCmd.Flags().Bool("fix", false, "Add the missing env file patterns to .gitignore")
+
+	envCmd.AddCommand(envDoctorCmd)
+}
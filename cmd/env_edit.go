@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/ui"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// envEditCmd edits an environment file in the user's editor
+var envEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the environment file in $EDITOR",
+	Long: `Opens a copy of the selected environment file in $VISUAL or $EDITOR. When the editor closes, the file
+is parsed and validated against env.schema.json; on errors you can re-open the editor to fix them.
+The changes are then applied in one step and the type declarations are regenerated.
+
+With --secrets, the encrypted .env.enc (or .env.<name>.enc) is decrypted into the temporary copy and
+encrypted again after editing, so the plain values never reach the project directory. The key is
+obtained the same way as for 'env encrypt'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		if !ui.IsInteractive() {
+			fmt.Println("Error: env edit opens an editor and requires a terminal")
+			return
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFilePath := dotenv.EnvFilePath(projectPath, envName)
+		encPath := dotenv.EncryptedFilePath(projectPath, envName)
+
+		// Load the content to edit, decrypting it with --secrets
+		secretsMode, _ := cmd.Flags().GetBool("secrets")
+		var secretsKey dotenv.SecretsKey
+		var original string
+		if secretsMode {
+			secretsKey, err = secretsKeyFromFlags(cmd, false)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			decrypted, err := dotenv.DecryptEnvFile(encPath, "", secretsKey)
+			if err != nil {
+				fmt.Printf("Error decrypting %s: %v\n", filepath.Base(encPath), err)
+				return
+			}
+			original = decrypted.Render()
+		} else {
+			envFile, err := dotenv.LoadEnvFile(envFilePath)
+			if err != nil {
+				fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+				return
+			}
+			original = envFile.Render()
+		}
+
+		var schema *dotenv.Schema
+		if dotenv.HasSchema(projectPath) {
+			schema, err = dotenv.LoadSchema(projectPath)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+		edited, envFile, err := dotenv.EditUntilValid(dotenv.EnvFileName(envName), original, schema, ui.OpenEditor, ui.PromptConfirm)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if envFile == nil {
+			return
+		}
+
+		if secretsMode {
+			err = dotenv.EncryptEnvFile(envFile, encPath, projectConfig.SecretsKeyID, secretsKey)
+			if err != nil {
+				fmt.Printf("Error encrypting %s: %v\n", filepath.Base(encPath), err)
+				return
+			}
+			fmt.Printf("Updated %s.\n", filepath.Base(encPath))
+			return
+		}
+
+		err = dotenv.ReplaceEnvFile(envFilePath, edited)
+		if err != nil {
+			fmt.Printf("Error saving %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		// Keep .env and the type declarations in sync with the edited keys
+		err = syncActiveEnv(projectPath, envName, projectConfig)
+		if err != nil {
+			fmt.Printf("Error updating .env: %v\n", err)
+			return
+		}
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Updated %s.\n", dotenv.EnvFileName(envName))
+	},
+}
+
+func init() {
+	envEditCmd.Flags().Bool("secrets", false, "Edit the decrypted content of the encrypted env file")
+	addSecretsKeyFlags(envEditCmd)
+
+	envCmd.AddCommand(envEditCmd)
+}
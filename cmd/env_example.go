@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// envSyncExampleCmd regenerates .env.example from the environment file
+var envSyncExampleCmd = &cobra.Command{
+	Use:   "sync-example",
+	Short: "Regenerate .env.example from the environment file",
+	Long: `Writes .env.example with the keys, section headers and order of the selected environment file
+and its values blanked. Other comments are left out, as they may hold commented-out values.
+With --placeholders, values become <key> placeholders with a hint about the
+expected kind of value. Placeholders already written in .env.example are kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		// Load the selected environment file
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		placeholders, _ := cmd.Flags().GetBool("placeholders")
+		err = dotenv.SyncExample(projectPath, envFile, placeholders)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("%s updated from %s.\n", dotenv.ExampleFileName, dotenv.EnvFileName(envName))
+	},
+}
+
+// envCheckCmd compares the environment file with .env.example
+var envCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that the environment file and .env.example declare the same keys",
+	Long: `Compares the keys of the selected environment file with .env.example and lists the keys
+missing from either side. Exits with a non-zero status on any difference, so it can be used
+as a pre-commit hook or CI gate.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Load the selected environment file
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		missing, extra, err := dotenv.CheckExample(projectPath, envFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(missing) == 0 && len(extra) == 0 {
+			fmt.Printf("%s and %s are in sync.\n", dotenv.EnvFileName(envName), dotenv.ExampleFileName)
+			return
+		}
+
+		for _, key := range missing {
+			fmt.Printf("missing in %s: %s\n", dotenv.EnvFileName(envName), key)
+		}
+		for _, key := range extra {
+			fmt.Printf("missing in %s: %s\n", dotenv.ExampleFileName, key)
+		}
+		fmt.Println("Run 'env sync-example' to update the example file.")
+		os.Exit(1)
+	},
+}
+
+func init() {
+	envSyncExampleCmd.Flags().Bool("placeholders", false, "Replace values with placeholders and hints instead of blanking them")
+
+	envCmd.AddCommand(envSyncExampleCmd)
+	envCmd.AddCommand(envCheckCmd)
+}
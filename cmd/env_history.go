@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// envHistoryCmd lists the snapshots taken of the env files
+var envHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the snapshots taken of the environment files",
+	Long: `Lists the snapshots kept in .mirorim-cli/backups, newest first. A snapshot of the env files is taken
+before every command that changes or deletes them, encrypted with the project key when one is available.
+Restore one with 'env restore'. --json prints the snapshots as JSON.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		snapshots, err := dotenv.ListSnapshots(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing snapshots: %v\n", err)
+			os.Exit(1)
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if snapshots == nil {
+				snapshots = []dotenv.Snapshot{}
+			}
+			data, err := json.MarshalIndent(snapshots, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error serializing snapshots: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots.")
+			return
+		}
+		for _, snapshot := range snapshots {
+			fmt.Printf("%s  %s  %-16s %s\n", snapshot.ID, snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				snapshot.Operation, strings.Join(snapshotFileNames(snapshot), ", "))
+		}
+	},
+}
+
+// envRestoreCmd writes the files of a snapshot back into the project
+var envRestoreCmd = &cobra.Command{
+	Use:   "restore [snapshot]",
+	Short: "Restore the environment files from a snapshot",
+	Long: `Restores the env files of a snapshot listed by 'env history', or of the latest snapshot when none is given.
+The current files are snapshotted first, so a restore can be undone as well. Encrypted snapshots are
+decrypted with the key obtained the same way as for 'env decrypt'. Asks for confirmation unless --yes is passed.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		snapshot, err := dotenv.FindSnapshot(projectPath, id)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		names := snapshotFileNames(*snapshot)
+		confirmed, err := confirmAction(cmd, fmt.Sprintf("Restore %s from snapshot %s (%s)?", strings.Join(names, ", "), snapshot.ID, snapshot.Operation))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Aborted.")
+			return
+		}
+
+		secretsKey, err := secretsKeyFromFlags(cmd, false)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		err = dotenv.RestoreSnapshot(projectPath, snapshot, secretsKey)
+		if err != nil {
+			fmt.Printf("Error restoring snapshot %s: %v\n", snapshot.ID, err)
+			return
+		}
+
+		// Restoring .env undoes 'env destroy'
+		for _, name := range names {
+			if name == ".env" && !projectConfig.EnvInitialized {
+				err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+					cfg.EnvInitialized = true
+				})
+				if err != nil {
+					fmt.Printf("Error saving project config: %v\n", err)
+					return
+				}
+			}
+		}
+
+		// Keep .env and the type declarations in sync with the restored keys
+		if projectConfig.ActiveEnv != "" {
+			for _, name := range names {
+				if name == dotenv.EnvFileName(projectConfig.ActiveEnv) {
+					err = syncActiveEnv(projectPath, projectConfig.ActiveEnv, projectConfig)
+					if err != nil {
+						fmt.Printf("Error updating .env: %v\n", err)
+						return
+					}
+				}
+			}
+		}
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Restored %s from snapshot %s.\n", strings.Join(names, ", "), snapshot.ID)
+	},
+}
+
+// envRetentionCmd shows or changes how many snapshots are kept
+var envRetentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Show or change how long snapshots of the environment files are kept",
+	Long: fmt.Sprintf(`Shows the retention policy of the snapshots listed by 'env history', or changes it with
+--max-snapshots and --max-age-days. By default the latest %d snapshots of the last %d days are kept;
+a negative value removes the limit. Snapshots exceeding the policy are deleted right away.`,
+		config.DefaultMaxSnapshots, config.DefaultMaxAgeDays),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		maxSnapshotsChanged := cmd.Flags().Changed("max-snapshots")
+		maxAgeChanged := cmd.Flags().Changed("max-age-days")
+		if maxSnapshotsChanged || maxAgeChanged {
+			maxSnapshots, _ := cmd.Flags().GetInt("max-snapshots")
+			maxAgeDays, _ := cmd.Flags().GetInt("max-age-days")
+			err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+				if cfg.BackupRetention == nil {
+					cfg.BackupRetention = &config.BackupRetention{}
+				}
+				if maxSnapshotsChanged {
+					cfg.BackupRetention.MaxSnapshots = maxSnapshots
+				}
+				if maxAgeChanged {
+					cfg.BackupRetention.MaxAgeDays = maxAgeDays
+				}
+				projectConfig = cfg
+			})
+			if err != nil {
+				fmt.Printf("Error saving project config: %v\n", err)
+				return
+			}
+
+			err = dotenv.PruneSnapshots(projectPath, projectConfig.BackupRetention)
+			if err != nil {
+				fmt.Printf("Error pruning snapshots: %v\n", err)
+				return
+			}
+		}
+
+		maxSnapshots, maxAge := projectConfig.BackupRetention.Limits()
+		if maxSnapshots > 0 {
+			fmt.Printf("Snapshots kept: %d\n", maxSnapshots)
+		} else {
+			fmt.Println("Snapshots kept: unlimited")
+		}
+		if maxAge > 0 {
+			fmt.Printf("Maximum age: %d days\n", int(maxAge.Hours()/24))
+		} else {
+			fmt.Println("Maximum age: unlimited")
+		}
+	},
+}
+
+// snapshotFileNames returns the names of the files kept in a snapshot
+func snapshotFileNames(snapshot dotenv.Snapshot) []string {
+	names := make([]string, 0, len(snapshot.Files))
+	for _, file := range snapshot.Files {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+func init() {
+	envHistoryCmd.Flags().Bool("json", false, "Print the snapshots as JSON")
+	addSecretsKeyFlags(envRestoreCmd)
+	envRestoreCmd.Flags().BoolP("yes", "y", false, "Restore without asking for confirmation")
+	envRetentionCmd.Flags().Int("max-snapshots", 0, "Number of snapshots to keep (0 for the default, negative for no limit)")
+	envRetentionCmd.Flags().Int("max-age-days", 0, "Days to keep snapshots for (0 for the default, negative for no limit)")
+
+	envCmd.AddCommand(envHistoryCmd)
+	envCmd.AddCommand(envRestoreCmd)
+	envCmd.AddCommand(envRetentionCmd)
+}
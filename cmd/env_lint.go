@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// envLintCmd cross-references the env file with the variables read by the sources
+var envLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Find unused, undefined and wrongly prefixed environment variables",
+	Long: `Scans the JavaScript and TypeScript sources of the project for imports from @env (or the module set with
+'env configure'), imports from the generated Expo accessor module and process.env accesses, and cross-references
+them with the selected env file.
+node_modules, the native ios and android projects and build output are skipped.
+
+Reported issues:
+  unused         a key of the env file that no source reads
+  undefined      a source reads a key the env file does not define
+  wrong-prefix   an Expo app reads process.env without the EXPO_PUBLIC_ prefix, so the value is not inlined
+  exposed        a key marked build-time-only or server-only in env.schema.json can reach the app bundle
+
+Exits with a non-zero status when issues are found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			os.Exit(2)
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			os.Exit(2)
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(2)
+		}
+
+		refs, err := dotenv.ScanSources(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		var schema *dotenv.Schema
+		if dotenv.HasSchema(projectPath) {
+			schema, err = dotenv.LoadSchema(projectPath)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(2)
+			}
+		}
+		issues := dotenv.Lint(envFile, dotenv.EnvFileName(envName), refs, projectConfig.ProjectType, schema, projectConfig.DotenvPlugin)
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if issues == nil {
+				issues = []dotenv.LintIssue{}
+			}
+			data, err := json.MarshalIndent(issues, "", "  ")
+			if err != nil {
+				fmt.Printf("Error serializing issues: %v\n", err)
+				os.Exit(2)
+			}
+			fmt.Println(string(data))
+		} else if len(issues) == 0 {
+			fmt.Printf("No issues found in %s.\n", dotenv.EnvFileName(envName))
+		} else {
+			for _, issue := range issues {
+				fmt.Printf("%s:%d: %s: %s\n", issue.File, issue.Line, issue.Kind, issue.Message)
+			}
+			fmt.Printf("\n%d %s found.\n", len(issues), pluralize(len(issues), "issue", "issues"))
+		}
+
+		if len(issues) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	envLintCmd.Flags().Bool("json", false, "Print the issues as JSON")
+
+	envCmd.AddCommand(envLintCmd)
+}
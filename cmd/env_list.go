@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// envListCmd prints the variables of an environment file
+var envListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List environment variables with masked values",
+	Long: `Prints the keys of the selected environment file in sorted order, with their values masked.
+Use --reveal to show the values, --keys-only to print only the keys, and --json for machine-readable output.
+In Expo projects, keys without the EXPO_PUBLIC_ prefix are marked as not exposed to the app.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		// Load the selected environment file
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		reveal, _ := cmd.Flags().GetBool("reveal")
+		keysOnly, _ := cmd.Flags().GetBool("keys-only")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		keys := envFile.ListKeys()
+		sort.Strings(keys)
+
+		if asJSON {
+			err = printEnvListJSON(envFile, keys, projectConfig.ProjectType, reveal, keysOnly)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			return
+		}
+
+		for _, key := range keys {
+			if keysOnly {
+				fmt.Println(key)
+				continue
+			}
+
+			value := envFile.Variables[key]
+			if !reveal {
+				value = dotenv.MaskValue(value)
+			}
+			line := fmt.Sprintf("%s=%s", key, value)
+			if !dotenv.IsPublicKey(key, projectConfig.ProjectType) {
+				line += "  (not exposed, missing " + dotenv.ExpoPublicPrefix + " prefix)"
+			}
+			fmt.Println(line)
+		}
+	},
+}
+
+// envGetCmd prints the raw value of a single variable
+var envGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the value of an environment variable",
+	Long: `Prints the raw value of KEY from the selected environment file, so it can be used in shell scripts.
+In Expo projects the EXPO_PUBLIC_ prefix may be omitted. Exits with a non-zero status if the key is not defined.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Errors go to stderr so they never end up in a captured value
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+			os.Exit(1)
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		key, ok := envFile.ResolveKey(args[0], projectConfig.ProjectType)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: %s is not defined in %s\n", args[0], dotenv.EnvFileName(envName))
+			os.Exit(1)
+		}
+
+		fmt.Println(envFile.Variables[key])
+	},
+}
+
+// envListEntry is the JSON representation of a variable printed by env list
+type envListEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Public bool   `json:"public"`
+}
+
+// printEnvListJSON prints the variables as a JSON array of entries, or of keys with keysOnly
+func printEnvListJSON(envFile *dotenv.DotenvFile, keys []string, projectType string, reveal, keysOnly bool) error {
+	var output interface{} = keys
+	if !keysOnly {
+		entries := make([]envListEntry, 0, len(keys))
+		for _, key := range keys {
+			value := envFile.Variables[key]
+			if !reveal {
+				value = dotenv.MaskValue(value)
+			}
+			entries = append(entries, envListEntry{
+				Key:    key,
+				Value:  value,
+				Public: dotenv.IsPublicKey(key, projectType),
+			})
+		}
+		output = entries
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize variables: %v", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func init() {
+	envListCmd.Flags().Bool("reveal", false, "Show values instead of masking them")
+	envListCmd.Flags().Bool("keys-only", false, "Print only the keys")
+	envListCmd.Flags().Bool("json", false, "Print the variables as JSON")
+
+	envCmd.AddCommand(envListCmd)
+	envCmd.AddCommand(envGetCmd)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/utils"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// envRenameCmd renames a variable across the env files, schema and sources
+var envRenameCmd = &cobra.Command{
+	Use:   "rename OLD NEW",
+	Short: "Rename an environment variable everywhere it is used",
+	Long: `Renames OLD to NEW in every env file, in env.schema.json and in references like ${OLD} inside other values.
+In the JavaScript and TypeScript sources, imports from @env, imports from the generated Expo accessor module
+and process.env.OLD accesses are rewritten. The type declarations are regenerated afterwards.
+In Expo projects, NEW gets the EXPO_PUBLIC_ prefix like with env add.
+
+The changes are printed as a diff first and applied after confirmation. Use --dry-run to only print them
+and --yes to apply them without asking. Encrypted .env.enc files are not changed, run env encrypt again.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		for _, key := range args {
+			if err := utils.ValidateEnvKey(key); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+
+		oldKey, err := resolveRenamedKey(projectPath, args[0], projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// A build-time-only or server-only variable stays one under its new name
+		schema, err := loadOrCreateSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		var newKey string
+		if exposure, secret := schema.SecretExposure(oldKey); secret {
+			newKey, err = secretKey(args[1], exposure, projectConfig, schema)
+		} else {
+			newKey, err = exposedKey(args[1], projectConfig, schema)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if oldKey == newKey {
+			fmt.Printf("%s already has that name.\n", oldKey)
+			return
+		}
+
+		changes, err := dotenv.PlanRename(projectPath, projectConfig.ProjectType, oldKey, newKey)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		for _, change := range changes {
+			fmt.Print(change.LineDiff())
+		}
+		fmt.Printf("\nRenaming %s to %s changes %d %s.\n", oldKey, newKey, len(changes), pluralize(len(changes), "file", "files"))
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return
+		}
+		confirmed, err := confirmAction(cmd, "Apply these changes?")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if !confirmed {
+			fmt.Println("Rename cancelled.")
+			return
+		}
+
+		err = dotenv.ApplyChanges(projectPath, changes)
+		if err != nil {
+			fmt.Printf("Error applying changes: %v\n", err)
+			return
+		}
+
+		// Regenerate the type declarations from the renamed keys
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Renamed %s to %s.\n", oldKey, newKey)
+		for _, env := range encryptedEnvironments(projectPath) {
+			fmt.Printf("Run 'mirorim-cli env encrypt%s' to update %s.\n", env.flag, env.file)
+		}
+	},
+}
+
+// resolveRenamedKey returns the key env rename works on. In Expo projects the
+// EXPO_PUBLIC_ prefix may be omitted, unless the unprefixed key is defined.
+func resolveRenamedKey(projectPath, key string, projectConfig *config.ProjectConfig) (string, error) {
+	keys, err := dotenv.ProjectKeys(projectPath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, candidate := range keyCandidates(key, projectConfig) {
+		for _, existing := range keys {
+			if existing == candidate {
+				return candidate, nil
+			}
+		}
+	}
+
+	// Keys only read by the sources can be renamed too
+	return normalizeKey(key, projectConfig), nil
+}
+
+// encryptedEnvironment is an environment with a committed encrypted file
+type encryptedEnvironment struct {
+	file string
+	flag string
+}
+
+// encryptedEnvironments lists the encrypted env files of the project
+func encryptedEnvironments(projectPath string) []encryptedEnvironment {
+	entries, err := os.ReadDir(projectPath)
+	if err != nil {
+		return nil
+	}
+
+	var envs []encryptedEnvironment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, ".env") || !strings.HasSuffix(name, dotenv.EncryptedSuffix) {
+			continue
+		}
+		env := strings.TrimPrefix(strings.TrimSuffix(name, dotenv.EncryptedSuffix), ".env")
+		flag := ""
+		if env != "" {
+			flag = " --env " + strings.TrimPrefix(env, ".")
+		}
+		envs = append(envs, encryptedEnvironment{file: name, flag: flag})
+	}
+	return envs
+}
+
+func init() {
+	envRenameCmd.Flags().Bool("dry-run", false, "Print the changes without applying them")
+	envRenameCmd.Flags().BoolP("yes", "y", false, "Apply the changes without asking for confirmation")
+
+	envCmd.AddCommand(envRenameCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// envResolveCmd prints the variables of an environment file with references expanded
+var envResolveCmd = &cobra.Command{
+	Use:   "resolve [KEY]",
+	Short: "Print environment variables with references expanded",
+	Long: `Expands ${VAR}, ${VAR:-default}, ${VAR-default} and $VAR references in the selected environment file
+and prints the resulting variables in sorted order, with their values masked unless --reveal is set.
+References are looked up in the same file, and in the process environment with --process-env.
+Single-quoted values are taken literally and "\$" escapes a dollar sign.
+With KEY, only the raw resolved value of that variable is printed.
+Exits with a non-zero status on reference cycles or malformed references.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Errors go to stderr so they never end up in a captured value
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+			os.Exit(1)
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		processEnv, _ := cmd.Flags().GetBool("process-env")
+		resolved, err := envFile.Resolve(processEnv)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		if len(args) == 1 {
+			key, ok := envFile.ResolveKey(args[0], projectConfig.ProjectType)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "Error: %s is not defined in %s\n", args[0], dotenv.EnvFileName(envName))
+				os.Exit(1)
+			}
+			fmt.Println(resolved[key])
+			return
+		}
+
+		reveal, _ := cmd.Flags().GetBool("reveal")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		keys := envFile.ListKeys()
+		sort.Strings(keys)
+
+		if !reveal {
+			for _, key := range keys {
+				resolved[key] = dotenv.MaskValue(resolved[key])
+			}
+		}
+
+		if asJSON {
+			data, err := json.MarshalIndent(resolved, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error serializing variables: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+			return
+		}
+
+		for _, key := range keys {
+			fmt.Printf("%s=%s\n", key, resolved[key])
+		}
+	},
+}
+
+func init() {
+	envResolveCmd.Flags().Bool("reveal", false, "Show values instead of masking them")
+	envResolveCmd.Flags().Bool("json", false, "Print the resolved variables as a JSON object")
+	envResolveCmd.Flags().Bool("process-env", false, "Fall back to the process environment for references not defined in the file")
+
+	envCmd.AddCommand(envResolveCmd)
+}
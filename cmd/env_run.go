@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// envRunCmd runs a command with the variables of an environment file applied
+var envRunCmd = &cobra.Command{
+	Use:   "run [--env name] -- COMMAND [ARGS...]",
+	Short: "Run a command with the project environment loaded",
+	Long: `Loads the selected environment file, expands its references and runs COMMAND with the variables
+merged over the current process environment. Variables from the file take precedence.
+Signals received by the CLI are forwarded to the command, and its exit code is propagated.
+
+Example:
+  mirorim-cli env run --env staging -- npx react-native start`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Errors go to stderr so they do not mix with the output of the command
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+			os.Exit(1)
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		vars, err := envFile.Resolve(true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		os.Exit(runWithEnv(args[0], args[1:], dotenv.MergeEnviron(os.Environ(), vars)))
+	},
+}
+
+// runWithEnv runs a command with the given environment, forwarding signals to
+// it, and returns its exit code
+func runWithEnv(name string, args []string, environ []string) int {
+	child := exec.Command(name, args...)
+	child.Env = environ
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	// Start listening before the child exists so no signal is lost
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+
+	if err := child.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting %s: %v\n", name, err)
+		return 127
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := child.Wait()
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			// Follow the shell convention for commands killed by a signal
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	}
+	fmt.Fprintf(os.Stderr, "Error running %s: %v\n", name, err)
+	return 1
+}
+
+func init() {
+	envCmd.AddCommand(envRunCmd)
+}
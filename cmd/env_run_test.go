@@ -0,0 +1,103 @@
+//go:build unix
+
+package cmd
+
+import (
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is the command run by the runWithEnv tests. It is not a
+// real test and does nothing unless started by them.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("MIRORIM_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		os.Exit(2)
+	}
+
+	switch args[1] {
+	case "exit":
+		// exit CODE
+		code, err := strconv.Atoi(args[2])
+		if err != nil {
+			os.Exit(2)
+		}
+		os.Exit(code)
+	case "getenv":
+		// getenv KEY VALUE exits with 0 when KEY is set to VALUE
+		if value, ok := os.LookupEnv(args[2]); ok && value == args[3] {
+			os.Exit(0)
+		}
+		os.Exit(1)
+	case "wait":
+		// wait FILE creates FILE and waits for a signal
+		if err := os.WriteFile(args[2], nil, 0644); err != nil {
+			os.Exit(2)
+		}
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+	os.Exit(2)
+}
+
+// runHelper runs the helper process with runWithEnv
+func runHelper(t *testing.T, vars map[string]string, args ...string) int {
+	t.Helper()
+	environ := dotenv.MergeEnviron(append(os.Environ(), "MIRORIM_HELPER_PROCESS=1"), vars)
+	return runWithEnv(os.Args[0], append([]string{"-test.run=^TestHelperProcess$", "--"}, args...), environ)
+}
+
+func TestRunWithEnvExitCode(t *testing.T) {
+	if code := runHelper(t, nil, "exit", "0"); code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if code := runHelper(t, nil, "exit", "3"); code != 3 {
+		t.Errorf("exit code = %d, want 3", code)
+	}
+}
+
+func TestRunWithEnvCommandNotFound(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "missing")
+	if code := runWithEnv(name, nil, os.Environ()); code != 127 {
+		t.Errorf("exit code = %d, want 127", code)
+	}
+}
+
+func TestRunWithEnvPrecedence(t *testing.T) {
+	t.Setenv("MIRORIM_TEST_VALUE", "process")
+	if code := runHelper(t, nil, "getenv", "MIRORIM_TEST_VALUE", "process"); code != 0 {
+		t.Error("the process environment is not passed to the command")
+	}
+	if code := runHelper(t, map[string]string{"MIRORIM_TEST_VALUE": "file"}, "getenv", "MIRORIM_TEST_VALUE", "file"); code != 0 {
+		t.Error("the env file does not take precedence over the process environment")
+	}
+}
+
+func TestRunWithEnvForwardsSignals(t *testing.T) {
+	ready := filepath.Join(t.TempDir(), "ready")
+	go func() {
+		// Signal the CLI once the command runs, runWithEnv forwards it
+		for i := 0; i < 500; i++ {
+			if _, err := os.Stat(ready); err == nil {
+				_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	if code := runHelper(t, nil, "wait", ready); code != 128+int(syscall.SIGTERM) {
+		t.Errorf("exit code = %d, want %d", code, 128+int(syscall.SIGTERM))
+	}
+}
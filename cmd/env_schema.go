@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// envSchemaCmd groups the commands managing env.schema.json
+var envSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Manage the typed schema of environment variables",
+	Long: `Manages env.schema.json, which declares for each variable its type (string, number,
+boolean, url or enum), whether it is required, its default and a description, and whether
+it may be inlined into the app bundle (public) or is build-time-only or server-only.
+The schema is used by 'env validate' and to generate typed declarations (env.d.ts or src/config/env.ts).`,
+}
+
+// envSchemaInitCmd creates env.schema.json from the current environment file
+var envSchemaInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create env.schema.json from the variables of the environment file",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if dotenv.HasSchema(projectPath) && !force {
+			fmt.Printf("%s already exists. Use --force to overwrite it.\n", dotenv.SchemaFileName)
+			return
+		}
+
+		projectConfig, envFile, ok := loadSchemaContext(cmd, projectPath)
+		if !ok {
+			return
+		}
+
+		schema := dotenv.InferSchema(envFile)
+		err = saveSchema(projectPath, projectConfig, schema)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Created %s with %d variables.\n", dotenv.SchemaFileName, len(schema.Variables))
+	},
+}
+
+// envSchemaSetCmd adds or replaces the definition of a variable
+var envSchemaSetCmd = &cobra.Command{
+	Use:   "set KEY",
+	Short: "Declare the type, default and description of a variable",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		projectConfig, _, ok := loadSchemaContext(cmd, projectPath)
+		if !ok {
+			return
+		}
+
+		schema, err := loadOrCreateSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// The exposure is kept unless given
+		exposure := ""
+		if cmd.Flags().Changed("exposure") {
+			exposure, _ = cmd.Flags().GetString("exposure")
+		}
+		key, err := schemaKey(args[0], projectConfig, schema, exposure)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		variable := &dotenv.VariableSchema{Exposure: storedExposure(exposure)}
+		if existing, exists := schema.Variables[key]; exists && exposure == "" {
+			variable.Exposure = existing.Exposure
+		}
+		variable.Type, _ = cmd.Flags().GetString("type")
+		variable.Values, _ = cmd.Flags().GetStringSlice("values")
+		variable.Required, _ = cmd.Flags().GetBool("required")
+		variable.Description, _ = cmd.Flags().GetString("description")
+		if cmd.Flags().Changed("default") {
+			defaultValue, _ := cmd.Flags().GetString("default")
+			variable.Default = &defaultValue
+		}
+
+		err = schema.SetVariable(key, variable)
+		if err != nil {
+			fmt.Printf("Error: invalid schema for %s: %v\n", key, err)
+			return
+		}
+
+		err = saveSchema(projectPath, projectConfig, schema)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Schema for %s saved.\n", key)
+	},
+}
+
+// envSchemaMarkCmd changes whether a variable may end up in the app bundle
+var envSchemaMarkCmd = &cobra.Command{
+	Use:   "mark KEY public|build|server",
+	Short: "Mark a variable as public, build-time-only or server-only",
+	Long: `Marks a variable as public (the default), build-time-only or server-only. Build-time-only and
+server-only variables must stay out of the app bundle: in Expo projects they are kept without the
+EXPO_PUBLIC_ prefix, in bare projects they are left out of the react-native-dotenv allowlist, which
+is generated from the public keys unless 'env configure' set one. 'env lint' reports the ones that can still be inlined or are read by
+the app, and they are left out of the generated type declarations.`,
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: dotenv.Exposures,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		projectConfig, _, ok := loadSchemaContext(cmd, projectPath)
+		if !ok {
+			return
+		}
+
+		schema, err := loadOrCreateSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		exposure := args[1]
+		if exposure != dotenv.ExposurePublic && !dotenv.IsSecretExposure(exposure) {
+			fmt.Printf("Error: unknown exposure %q, expected one of %s\n", exposure, strings.Join(dotenv.Exposures, ", "))
+			return
+		}
+
+		key, err := schemaKey(args[0], projectConfig, schema, exposure)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		variable, exists := schema.Variables[key]
+		if !exists {
+			variable = &dotenv.VariableSchema{Type: dotenv.TypeString}
+		}
+		variable.Exposure = storedExposure(exposure)
+		err = schema.SetVariable(key, variable)
+		if err != nil {
+			fmt.Printf("Error: invalid schema for %s: %v\n", key, err)
+			return
+		}
+
+		err = saveSchema(projectPath, projectConfig, schema)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Marked %s as %s.\n", key, dotenv.ExposureLabel(exposure))
+	},
+}
+
+// envSchemaRemoveCmd removes a variable from the schema
+var envSchemaRemoveCmd = &cobra.Command{
+	Use:   "remove KEY",
+	Short: "Remove a variable from the schema",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		projectConfig, _, ok := loadSchemaContext(cmd, projectPath)
+		if !ok {
+			return
+		}
+
+		schema, err := dotenv.LoadSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		key, err := schemaKey(args[0], projectConfig, schema, "")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if _, exists := schema.Variables[key]; !exists {
+			fmt.Printf("Error: %s is not declared in %s.\n", key, dotenv.SchemaFileName)
+			return
+		}
+		delete(schema.Variables, key)
+
+		err = saveSchema(projectPath, projectConfig, schema)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Removed %s from %s.\n", key, dotenv.SchemaFileName)
+	},
+}
+
+// envSchemaShowCmd prints the schema
+var envSchemaShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the schema of the environment variables",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		schema, err := dotenv.LoadSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	},
+}
+
+// envValidateCmd validates the environment file against the schema
+var envValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the environment file against env.schema.json",
+	Long: `Checks that every required variable of env.schema.json is set in the selected environment
+file and that each value matches its declared type. Exits with a non-zero status on errors.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		_, envFile, ok := loadSchemaContext(cmd, projectPath)
+		if !ok {
+			os.Exit(1)
+		}
+
+		schema, err := dotenv.LoadSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		errors := schema.Validate(envFile)
+		if len(errors) == 0 {
+			fmt.Printf("%s is valid.\n", filepath.Base(envFile.Path))
+			return
+		}
+
+		for _, validationErr := range errors {
+			fmt.Println(validationErr.Error())
+		}
+		fmt.Printf("%d %s failed validation.\n", len(errors), pluralize(len(errors), "variable", "variables"))
+		os.Exit(1)
+	},
+}
+
+// loadSchemaContext loads the project config and the selected environment
+// file, printing any error. ok is false when the command should stop.
+func loadSchemaContext(cmd *cobra.Command, projectPath string) (*config.ProjectConfig, *dotenv.DotenvFile, bool) {
+	projectConfig, err := config.LoadConfig(projectPath)
+	if err != nil {
+		fmt.Printf("Error loading project config: %v\n", err)
+		return nil, nil, false
+	}
+
+	envName, err := resolveEnvName(cmd, projectConfig)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return nil, nil, false
+	}
+	envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+	if err != nil {
+		fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+		return nil, nil, false
+	}
+
+	return projectConfig, envFile, true
+}
+
+// loadOrCreateSchema loads env.schema.json, or returns an empty schema if it does not exist yet
+func loadOrCreateSchema(projectPath string) (*dotenv.Schema, error) {
+	if !dotenv.HasSchema(projectPath) {
+		return &dotenv.Schema{Variables: make(map[string]*dotenv.VariableSchema)}, nil
+	}
+	return dotenv.LoadSchema(projectPath)
+}
+
+// schemaKey returns the schema key of a variable. Build-time-only and
+// server-only variables are declared without the EXPO_PUBLIC_ prefix. An
+// empty exposure is taken from the existing declaration of the key.
+func schemaKey(key string, projectConfig *config.ProjectConfig, schema *dotenv.Schema, exposure string) (string, error) {
+	key = strings.ToUpper(key)
+	secret := dotenv.IsSecretExposure(exposure)
+	if variable, exists := schema.Variables[key]; exists && exposure == "" {
+		secret = variable.IsSecret()
+	}
+	if !secret {
+		return normalizeKey(key, projectConfig), nil
+	}
+
+	if projectConfig.ProjectType == "expo" && strings.HasPrefix(key, dotenv.ExpoPublicPrefix) {
+		return "", fmt.Errorf("%s cannot be kept out of the app bundle with the %s prefix, declare it as %s", key, dotenv.ExpoPublicPrefix, strings.TrimPrefix(key, dotenv.ExpoPublicPrefix))
+	}
+	return key, nil
+}
+
+// storedExposure returns the exposure as stored in the schema, public being the default
+func storedExposure(exposure string) string {
+	if exposure == dotenv.ExposurePublic {
+		return ""
+	}
+	return exposure
+}
+
+// saveSchema writes the schema and regenerates the type declarations
+func saveSchema(projectPath string, projectConfig *config.ProjectConfig, schema *dotenv.Schema) error {
+	err := dotenv.SaveSchema(projectPath, schema)
+	if err != nil {
+		return err
+	}
+
+	err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+	if err != nil {
+		return fmt.Errorf("failed to update type declarations: %v", err)
+	}
+	return nil
+}
+
+// pluralize returns singular for a count of one and plural otherwise
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+func init() {
+	envSchemaInitCmd.Flags().Bool("force", false, "Overwrite an existing schema")
+	envSchemaSetCmd.Flags().String("type", dotenv.TypeString, "Variable type: "+strings.Join(dotenv.SchemaTypes, ", "))
+	envSchemaSetCmd.Flags().StringSlice("values", nil, "Allowed values for the enum type (comma separated)")
+	envSchemaSetCmd.Flags().Bool("required", false, "Mark the variable as required")
+	envSchemaSetCmd.Flags().String("default", "", "Default value, documented in the generated type declarations")
+	envSchemaSetCmd.Flags().String("description", "", "Description, used as JSDoc in the generated type declarations")
+	envSchemaSetCmd.Flags().String("exposure", dotenv.ExposurePublic, "Exposure: "+strings.Join(dotenv.Exposures, ", ")+" (build and server keep the variable out of the app bundle)")
+
+	envSchemaCmd.AddCommand(envSchemaInitCmd)
+	envSchemaCmd.AddCommand(envSchemaSetCmd)
+	envSchemaCmd.AddCommand(envSchemaMarkCmd)
+	envSchemaCmd.AddCommand(envSchemaRemoveCmd)
+	envSchemaCmd.AddCommand(envSchemaShowCmd)
+	envCmd.AddCommand(envSchemaCmd)
+	envCmd.AddCommand(envValidateCmd)
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/ui"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// envEncryptCmd writes the encrypted counterpart of an environment file
+var envEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the environment file into a committable .env.enc",
+	Long: `Writes .env.enc (or .env.<name>.enc with --env) where every value is encrypted with AES-GCM
+while keys and section headers stay readable, so the file can be committed and diffed key by key.
+A section header is a short title like "# API" naming the keys below it (API_URL, API_KEY).
+Other comments are encrypted as well, since they may hold values.
+
+The key is read from --key-file, from the MIRORIM_ENV_KEY variable, or from the project key file in
+~/.mirorim-cli/keys, which is generated on first use. With --passphrase the key is derived from a
+passphrase instead (read from MIRORIM_ENV_PASSPHRASE or prompted, twice on first use).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		// Load the selected environment file
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		// A new passphrase is typed twice, as a typo would lock the file
+		encPath := dotenv.EncryptedFilePath(projectPath, envName)
+		secretsKey, err := secretsKeyFromFlags(cmd, !dotenv.UsesPassphrase(encPath))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Name the project key on first use so its key file can be found later
+		if projectConfig.SecretsKeyID == "" {
+			keyID, err := dotenv.NewKeyID()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+				cfg.SecretsKeyID = keyID
+			})
+			if err != nil {
+				fmt.Printf("Error saving project config: %v\n", err)
+				return
+			}
+			projectConfig.SecretsKeyID = keyID
+		}
+
+		err = dotenv.EncryptEnvFile(envFile, encPath, projectConfig.SecretsKeyID, secretsKey)
+		if err != nil {
+			fmt.Printf("Error encrypting %s: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		fmt.Printf("Encrypted %s into %s.\n", dotenv.EnvFileName(envName), filepath.Base(encPath))
+	},
+}
+
+// envDecryptCmd restores an environment file from its encrypted counterpart
+var envDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt .env.enc into the environment file",
+	Long: `Decrypts .env.enc (or .env.<name>.enc with --env) into .env (or .env.<name>).
+The key is obtained the same way as for 'env encrypt'. Overwriting an environment file
+that already has variables asks for confirmation unless --yes is passed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Ask before replacing variables that may not be in the encrypted file
+		envFilePath := dotenv.EnvFilePath(projectPath, envName)
+		if existing, err := dotenv.ReadEnvFile(envFilePath); err == nil && len(existing.ListKeys()) > 0 {
+			confirmed, err := confirmAction(cmd, fmt.Sprintf("Overwrite %s?", dotenv.EnvFileName(envName)))
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			if !confirmed {
+				fmt.Println("Aborted.")
+				return
+			}
+		}
+
+		err = decryptEnvironment(cmd, projectPath, envName)
+		if err != nil {
+			fmt.Printf("Error decrypting %s: %v\n", filepath.Base(dotenv.EncryptedFilePath(projectPath, envName)), err)
+			return
+		}
+
+		// Keep .env and the type declarations in sync with the restored keys
+		err = syncActiveEnv(projectPath, envName, projectConfig)
+		if err != nil {
+			fmt.Printf("Error updating .env: %v\n", err)
+			return
+		}
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Decrypted %s.\n", dotenv.EnvFileName(envName))
+	},
+}
+
+// decryptEnvironment decrypts the encrypted file of an environment into its env file
+func decryptEnvironment(cmd *cobra.Command, projectPath, envName string) error {
+	secretsKey, err := secretsKeyFromFlags(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	envFile, err := dotenv.DecryptEnvFile(dotenv.EncryptedFilePath(projectPath, envName), dotenv.EnvFilePath(projectPath, envName), secretsKey)
+	if err != nil {
+		return err
+	}
+	return envFile.SaveEnvFile()
+}
+
+// secretsKeyFromFlags builds the key source from --key-file and --passphrase.
+// The passphrase is read from MIRORIM_ENV_PASSPHRASE or prompted for, twice
+// with confirm.
+func secretsKeyFromFlags(cmd *cobra.Command, confirm bool) (dotenv.SecretsKey, error) {
+	var secretsKey dotenv.SecretsKey
+	secretsKey.KeyFile, _ = cmd.Flags().GetString("key-file")
+
+	usePassphrase, _ := cmd.Flags().GetBool("passphrase")
+	if !usePassphrase {
+		return secretsKey, nil
+	}
+
+	secretsKey.Passphrase = os.Getenv(dotenv.PassphraseEnvVar)
+	if secretsKey.Passphrase != "" {
+		return secretsKey, nil
+	}
+	if !ui.IsInteractive() {
+		return secretsKey, fmt.Errorf("set %s to pass the passphrase when no terminal is attached", dotenv.PassphraseEnvVar)
+	}
+
+	passphrase, err := ui.PromptPassword("Enter the passphrase:")
+	if err != nil {
+		return secretsKey, err
+	}
+	if confirm {
+		repeated, err := ui.PromptPassword("Enter the passphrase again:")
+		if err != nil {
+			return secretsKey, err
+		}
+		if repeated != passphrase {
+			return secretsKey, fmt.Errorf("the passphrases do not match")
+		}
+	}
+	secretsKey.Passphrase = passphrase
+	return secretsKey, nil
+}
+
+// addSecretsKeyFlags registers the flags selecting the encryption key
+func addSecretsKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().String("key-file", "", "File holding the base64 encoded project key")
+	cmd.Flags().Bool("passphrase", false, "Derive the key from a passphrase instead of a key file")
+}
+
+func init() {
+	addSecretsKeyFlags(envEncryptCmd)
+	addSecretsKeyFlags(envDecryptCmd)
+	envDecryptCmd.Flags().BoolP("yes", "y", false, "Overwrite the environment file without asking for confirmation")
+
+	envCmd.AddCommand(envEncryptCmd)
+	envCmd.AddCommand(envDecryptCmd)
+}
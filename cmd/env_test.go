@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"mirorim-cli/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// newTestProject creates an initialized project of the given type holding
+// files, with the user directories pointed to temporary ones
+func newTestProject(t *testing.T, projectType string, files map[string]string) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("MIRORIM_ENV_KEY", "")
+
+	projectPath := t.TempDir()
+	for name, content := range files {
+		writeTestFile(t, projectPath, name, content)
+	}
+	err := config.SaveConfig(projectPath, &config.ProjectConfig{ProjectType: projectType, EnvInitialized: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return projectPath
+}
+
+// writeTestFile writes a file of the project, creating its directory
+func writeTestFile(t *testing.T, projectPath, name, content string) {
+	path := filepath.Join(projectPath, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTestFile returns the content of a file of the project, "" when it is missing
+func readTestFile(t *testing.T, projectPath, name string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(name)))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// runCLI runs mirorim-cli with args in projectPath and returns what it printed.
+// Flags keep their value between runs of a command, so they are reset first.
+func runCLI(t *testing.T, projectPath string, args ...string) string {
+	resetFlags(rootCmd)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(projectPath); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	output := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, reader)
+		output <- buf.String()
+	}()
+
+	rootCmd.SetArgs(args)
+	err = rootCmd.Execute()
+	writer.Close()
+	os.Stdout = stdout
+	printed := <-output
+	if err != nil {
+		t.Fatalf("mirorim-cli %s: %v\n%s", strings.Join(args, " "), err, printed)
+	}
+	return printed
+}
+
+// resetFlags sets the flags of cmd and its subcommands back to their default
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if list, ok := flag.Value.(pflag.SliceValue); ok {
+			list.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestEnvUseMaterializesTheActiveEnvironment(t *testing.T) {
+	projectPath := newTestProject(t, "bare", map[string]string{
+		".env.staging":    "API_URL=https://staging.example.com\n",
+		".env.production": "API_URL=https://example.com\n",
+	})
+
+	output := runCLI(t, projectPath, "env", "use", "staging")
+	if !strings.Contains(output, "Active environment is now staging.") {
+		t.Fatalf("env use printed %q", output)
+	}
+	projectConfig, err := config.LoadConfig(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectConfig.ActiveEnv != "staging" {
+		t.Errorf("active environment = %q, want staging", projectConfig.ActiveEnv)
+	}
+	env := readTestFile(t, projectPath, ".env")
+	if !strings.HasPrefix(env, "# Generated by mirorim-cli from .env.staging.") || !strings.Contains(env, "API_URL=https://staging.example.com\n") {
+		t.Errorf(".env = %q, want the materialized staging file", env)
+	}
+
+	// Without --env, commands work on the active environment and keep .env in sync
+	runCLI(t, projectPath, "env", "add", "TIMEOUT=10")
+	if got := readTestFile(t, projectPath, ".env.staging"); got != "API_URL=https://staging.example.com\nTIMEOUT=10\n" {
+		t.Errorf(".env.staging = %q", got)
+	}
+	if env := readTestFile(t, projectPath, ".env"); !strings.Contains(env, "TIMEOUT=10\n") {
+		t.Errorf(".env was not synced with .env.staging: %q", env)
+	}
+
+	// Other environments leave .env alone
+	before := readTestFile(t, projectPath, ".env")
+	runCLI(t, projectPath, "env", "add", "--env", "production", "RETRIES=3")
+	if got := readTestFile(t, projectPath, ".env.production"); got != "API_URL=https://example.com\nRETRIES=3\n" {
+		t.Errorf(".env.production = %q", got)
+	}
+	if after := readTestFile(t, projectPath, ".env"); after != before {
+		t.Errorf("changing .env.production rewrote .env: %q", after)
+	}
+
+	// Switching again replaces the materialized file
+	runCLI(t, projectPath, "env", "use", "production")
+	if env := readTestFile(t, projectPath, ".env"); !strings.Contains(env, "RETRIES=3\n") || strings.Contains(env, "TIMEOUT") {
+		t.Errorf(".env = %q, want the materialized production file", env)
+	}
+}
+
+func TestEnvUseKeepsAnUnmanagedEnvFile(t *testing.T) {
+	projectPath := newTestProject(t, "bare", map[string]string{
+		".env":         "LOCAL=1\n",
+		".env.staging": "API_URL=https://staging.example.com\n",
+	})
+
+	output := runCLI(t, projectPath, "env", "use", "staging")
+	if !strings.Contains(output, "Error") {
+		t.Errorf("env use printed %q, want an error", output)
+	}
+	if got := readTestFile(t, projectPath, ".env"); got != "LOCAL=1\n" {
+		t.Errorf(".env = %q, want it unchanged", got)
+	}
+
+	runCLI(t, projectPath, "env", "use", "staging", "--force")
+	if got := readTestFile(t, projectPath, ".env"); !strings.Contains(got, "API_URL=") {
+		t.Errorf(".env = %q after --force, want the materialized staging file", got)
+	}
+}
+
+func TestEnvNameSelection(t *testing.T) {
+	projectPath := newTestProject(t, "bare", map[string]string{".env": "A=1\n"})
+
+	for _, name := range []string{"../x", "example", "a.b"} {
+		output := runCLI(t, projectPath, "env", "add", "--env", name, "B=2")
+		if !strings.HasPrefix(output, "Error") {
+			t.Errorf("env add --env %s printed %q, want an error", name, output)
+		}
+	}
+	if got := readTestFile(t, projectPath, ".env"); got != "A=1\n" {
+		t.Errorf(".env = %q, want it unchanged", got)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(projectPath), "x*")); len(matches) > 0 {
+		t.Errorf("files were written outside the project: %v", matches)
+	}
+
+	// Without an active environment, .env is the default
+	runCLI(t, projectPath, "env", "add", "B=2")
+	if got := readTestFile(t, projectPath, ".env"); got != "A=1\nB=2\n" {
+		t.Errorf(".env = %q", got)
+	}
+}
+
+func TestEnvUpdateAndRemoveMixedCaseKeys(t *testing.T) {
+	projectPath := newTestProject(t, "bare", map[string]string{".env": "apiKey=1\nB=2\n"})
+
+	runCLI(t, projectPath, "env", "update", "apiKey=x", "--yes")
+	if got := readTestFile(t, projectPath, ".env"); got != "apiKey=x\nB=2\n" {
+		t.Errorf("after env update, .env = %q", got)
+	}
+
+	runCLI(t, projectPath, "env", "remove", "apiKey", "--yes")
+	if got := readTestFile(t, projectPath, ".env"); got != "B=2\n" {
+		t.Errorf("after env remove, .env = %q", got)
+	}
+
+	// Keys that are not defined as written are upper-cased
+	runCLI(t, projectPath, "env", "update", "b=3", "--yes")
+	if got := readTestFile(t, projectPath, ".env"); got != "B=3\n" {
+		t.Errorf("after env update b, .env = %q", got)
+	}
+}
+
+func TestEnvNonInteractiveFlags(t *testing.T) {
+	projectPath := newTestProject(t, "bare", map[string]string{".env": "A=1\n"})
+
+	// New keys need no confirmation
+	runCLI(t, projectPath, "env", "add", "B=2", "--key", "C", "--value", "x y")
+	if got := readTestFile(t, projectPath, ".env"); got != "A=1\nB=2\nC='x y'\n" {
+		t.Fatalf("after env add, .env = %q", got)
+	}
+
+	// Overwrites are refused without a terminal unless --yes is given
+	for _, args := range [][]string{
+		{"env", "add", "A=5"},
+		{"env", "update", "A=5"},
+		{"env", "remove", "A"},
+	} {
+		output := runCLI(t, projectPath, args...)
+		if !strings.Contains(output, "pass --yes") {
+			t.Errorf("mirorim-cli %s printed %q, want a confirmation error", strings.Join(args, " "), output)
+		}
+	}
+	if got := readTestFile(t, projectPath, ".env"); got != "A=1\nB=2\nC='x y'\n" {
+		t.Fatalf("refused commands changed .env: %q", got)
+	}
+
+	// Setting the same value is not an overwrite
+	runCLI(t, projectPath, "env", "add", "A=1")
+
+	runCLI(t, projectPath, "env", "add", "A=5", "--yes")
+	runCLI(t, projectPath, "env", "update", "--key", "B", "--value", "7", "-y")
+	runCLI(t, projectPath, "env", "remove", "--key", "C", "--yes")
+	if got := readTestFile(t, projectPath, ".env"); got != "A=5\nB=7\n" {
+		t.Errorf("after confirmed commands, .env = %q", got)
+	}
+
+	// Updating a key that does not exist fails
+	output := runCLI(t, projectPath, "env", "update", "MISSING=1", "--yes")
+	if !strings.Contains(output, "MISSING is not defined in .env") {
+		t.Errorf("env update MISSING printed %q", output)
+	}
+	if got := readTestFile(t, projectPath, ".env"); got != "A=5\nB=7\n" {
+		t.Errorf("a failed update changed .env: %q", got)
+	}
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/fsutil"
+	"mirorim-cli/internal/ui"
+	"mirorim-cli/internal/utils"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Conflict policies for env import
+const (
+	conflictKeep      = "keep"
+	conflictOverwrite = "overwrite"
+	conflictPrompt    = "prompt"
+)
+
+// envExportCmd prints the variables of an environment file in another format
+var envExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export environment variables as JSON, YAML, shell, GitHub Actions or docker format",
+	Long: `Prints the variables of the selected environment file in the format given by --format, with
+references expanded, so they can be moved to CI secrets or other tools:
+  json     a flat JSON object
+  yaml     a flat YAML mapping
+  sh       export KEY='value' lines for POSIX shells
+  github   KEY=value lines for $GITHUB_ENV, with heredocs for multiline values
+  docker   KEY=value lines for docker --env-file
+  dotenv   a .env file
+Values are not masked. Use --output to write to a file instead of standard output.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Errors go to stderr so they never end up in the exported data
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading project config: %v\n", err)
+			os.Exit(1)
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		envFile, err := dotenv.ReadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		resolved, err := envFile.Resolve(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error resolving %s: %v\n", dotenv.EnvFileName(envName), err)
+			os.Exit(1)
+		}
+
+		var vars []dotenv.Variable
+		for _, key := range envFile.ListKeys() {
+			vars = append(vars, dotenv.Variable{Key: key, Value: resolved[key]})
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		output, err := dotenv.Export(vars, format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		outputPath, _ := cmd.Flags().GetString("output")
+		if outputPath == "" {
+			fmt.Print(output)
+			return
+		}
+
+		// The exported file holds plain secrets, so keep it private
+		err = fsutil.WriteFile(outputPath, []byte(output), 0600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", outputPath, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d %s to %s.\n", len(vars), pluralize(len(vars), "variable", "variables"), outputPath)
+	},
+}
+
+// envImportCmd merges variables from a file into an environment file
+var envImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import environment variables from a JSON, YAML, shell, GitHub Actions or docker file",
+	Long: `Merges the variables of FILE into the selected environment file. The format is taken from
+--format, or guessed from the file extension (.json, .yaml, .yml, .sh, otherwise dotenv).
+Use - as FILE to read from standard input.
+
+Keys that already exist with a different value are handled according to --on-conflict:
+  keep        leave the existing value (default)
+  overwrite   replace the existing value
+  prompt      ask for each conflicting key
+Use --dry-run to print what would change without writing anything.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Ensure the environment has been initialized
+		envInitialized, err := dotenv.CheckEnvInitialized(projectPath)
+		if err != nil {
+			fmt.Printf("Error checking env initialization: %v\n", err)
+			return
+		}
+		if !envInitialized {
+			fmt.Println("Environment is not initialized. Run 'env init' first.")
+			return
+		}
+
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		policy, _ := cmd.Flags().GetString("on-conflict")
+		if policy != conflictKeep && policy != conflictOverwrite && policy != conflictPrompt {
+			fmt.Printf("Error: unknown conflict policy %q, expected keep, overwrite or prompt\n", policy)
+			return
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if policy == conflictPrompt && !dryRun && !ui.IsInteractive() {
+			fmt.Println("Error: --on-conflict prompt requires a terminal")
+			return
+		}
+
+		// Read the variables to import
+		vars, err := readImportFile(cmd, args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		envName, err := resolveEnvName(cmd, projectConfig)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		envFile, err := dotenv.LoadEnvFile(dotenv.EnvFilePath(projectPath, envName))
+		if err != nil {
+			fmt.Printf("Error loading %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		// Keep the keys marked build-time-only or server-only out of the app bundle
+		schema, err := loadOrCreateSchema(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		keys := make([]string, len(vars))
+		for i, v := range vars {
+			keys[i], err = exposedKey(v.Key, projectConfig, schema)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+
+		// Merge the variables, resolving conflicts with the selected policy
+		added, updated, kept := 0, 0, 0
+		var merged []dotenv.Variable
+		for i, v := range vars {
+			key := keys[i]
+			current, exists := envFile.Variables[key]
+			switch {
+			case !exists:
+				added++
+				fmt.Printf("+ %s\n", key)
+			case current == v.Value:
+				continue
+			case policy == conflictKeep:
+				kept++
+				fmt.Printf("= %s (kept existing value)\n", key)
+				continue
+			case policy == conflictPrompt && !dryRun:
+				overwrite, err := ui.PromptConfirm(fmt.Sprintf("%s already exists with a different value. Overwrite it?", key))
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					return
+				}
+				if !overwrite {
+					kept++
+					continue
+				}
+				updated++
+				fmt.Printf("~ %s\n", key)
+			default:
+				updated++
+				fmt.Printf("~ %s\n", key)
+			}
+			envFile.AddOrUpdateKey(key, v.Value)
+			merged = append(merged, dotenv.Variable{Key: key, Value: v.Value})
+		}
+
+		summary := fmt.Sprintf("%d added, %d updated, %d kept", added, updated, kept)
+		if dryRun {
+			fmt.Printf("Dry run: %s. No changes were written.\n", summary)
+			return
+		}
+		if added == 0 && updated == 0 {
+			fmt.Printf("Nothing to import (%s).\n", summary)
+			return
+		}
+
+		// Save changes to the environment file
+		err = dotenv.UpdateEnvFile(envFile.Path, func(envFile *dotenv.DotenvFile) error {
+			for _, v := range merged {
+				envFile.AddOrUpdateKey(v.Key, v.Value)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("Error saving %s file: %v\n", dotenv.EnvFileName(envName), err)
+			return
+		}
+
+		// Keep .env in sync when the active environment changed
+		err = syncActiveEnv(projectPath, envName, projectConfig)
+		if err != nil {
+			fmt.Printf("Error updating .env: %v\n", err)
+			return
+		}
+
+		// Regenerate the type declarations from the updated keys
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error updating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("Imported into %s: %s.\n", dotenv.EnvFileName(envName), summary)
+	},
+}
+
+// readImportFile reads and parses the variables of an import file, or of
+// standard input when path is -
+func readImportFile(cmd *cobra.Command, path string) ([]dotenv.Variable, error) {
+	format, _ := cmd.Flags().GetString("format")
+
+	var data []byte
+	var err error
+	if path == "-" {
+		if format == "" {
+			return nil, fmt.Errorf("--format is required when reading from standard input")
+		}
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		if format == "" {
+			format = dotenv.FormatFromPath(path)
+		}
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	vars, err := dotenv.Import(string(data), format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	for _, v := range vars {
+		if err := utils.ValidateEnvKey(v.Key); err != nil {
+			return nil, fmt.Errorf("invalid key in %s: %v", path, err)
+		}
+	}
+	return vars, nil
+}
+
+func init() {
+	formats := strings.Join(dotenv.Formats, ", ")
+	envExportCmd.Flags().StringP("format", "f", dotenv.FormatJSON, "Output format: "+formats)
+	envExportCmd.Flags().StringP("output", "o", "", "Write to a file instead of standard output")
+
+	envImportCmd.Flags().StringP("format", "f", "", "Input format: "+formats+" (default: guessed from the file extension)")
+	envImportCmd.Flags().String("on-conflict", conflictKeep, "How to handle existing keys with a different value: keep, overwrite or prompt")
+	envImportCmd.Flags().Bool("dry-run", false, "Print what would change without writing anything")
+
+	envCmd.AddCommand(envExportCmd)
+	envCmd.AddCommand(envImportCmd)
+}
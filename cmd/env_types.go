@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// envTypesCmd regenerates the type declarations of the environment variables
+var envTypesCmd = &cobra.Command{
+	Use:   "types",
+	Short: "Regenerate the TypeScript declarations of the environment variables",
+	Long: `Rewrites the type declarations from scratch with the keys of .env and of every .env.<name>
+file, using the types of env.schema.json when present: env.d.ts for bare projects, and the typed
+src/config/env.ts accessor module for Expo projects. Use it after editing env files by hand.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Get current project path
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			return
+		}
+
+		// Load the project configuration
+		projectConfig, err := config.LoadConfig(projectPath)
+		if err != nil {
+			fmt.Printf("Error loading project config: %v\n", err)
+			return
+		}
+
+		err = dotenv.SyncTypes(projectPath, projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Error generating type declarations: %v\n", err)
+			return
+		}
+
+		fmt.Printf("%s regenerated.\n", dotenv.TypesFileName(projectConfig.ProjectType))
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envTypesCmd)
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/secrets"
+	"mirorim-cli/internal/ui"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// scanCmd groups the commands that inspect the project tree
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Scan the project for problems",
+	Long:  `Scans the files of the project, for example for secrets that should not be committed.`,
+}
+
+// scanSecretsCmd reports hardcoded secrets in the project files
+var scanSecretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Find hardcoded secrets in the project files",
+	Long: `Walks the project and reports private keys, AWS, Google, Stripe, GitHub and Slack credentials, JWTs and
+other high entropy strings found in its files. node_modules, ios/Pods, build output and env files are skipped.
+Add a "mirorim-cli:ignore-secret" comment to a line to silence a false positive.
+
+With --fix, you are asked for each secret held in a JavaScript or TypeScript string literal whether to move it
+into the selected env file. The literal is then replaced with process.env.KEY in Expo projects, or with an
+import from @env in bare projects.
+
+Exits with a non-zero status when secrets remain.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		projectPath, err := os.Getwd()
+		if err != nil {
+			fmt.Printf("Error getting current directory: %v\n", err)
+			os.Exit(2)
+		}
+
+		findings, err := secrets.Scan(projectPath)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+
+		if fix, _ := cmd.Flags().GetBool("fix"); fix && len(findings) > 0 {
+			findings, err = moveSecretsToEnv(cmd, projectPath, findings)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(2)
+			}
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			if findings == nil {
+				findings = []secrets.Finding{}
+			}
+			data, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				fmt.Printf("Error serializing findings: %v\n", err)
+				os.Exit(2)
+			}
+			fmt.Println(string(data))
+		} else if len(findings) == 0 {
+			fmt.Println("No secrets found.")
+		} else {
+			for _, finding := range findings {
+				fmt.Printf("%s:%d:%d: %s: %s\n", finding.File, finding.Line, finding.Column,
+					secrets.Describe(finding.Rule), dotenv.MaskValue(finding.Secret))
+			}
+			fmt.Printf("\n%d possible %s found.\n", len(findings), pluralize(len(findings), "secret", "secrets"))
+		}
+
+		if len(findings) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// moveSecretsToEnv offers to move every movable finding into the selected env
+// file and returns the findings that were left in place
+func moveSecretsToEnv(cmd *cobra.Command, projectPath string, findings []secrets.Finding) ([]secrets.Finding, error) {
+	if !ui.IsInteractive() {
+		return nil, fmt.Errorf("--fix asks before moving each secret and requires a terminal")
+	}
+
+	projectConfig, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config: %v", err)
+	}
+	if !projectConfig.EnvInitialized {
+		return nil, fmt.Errorf("environment is not initialized, run 'env init' first")
+	}
+
+	envName, err := resolveEnvName(cmd, projectConfig)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := loadOrCreateSchema(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewriting a source shifts the positions of the findings after the
+	// literal, so the project is scanned again after every move
+	declined := make(map[string]bool)
+	moved := 0
+	for {
+		var next *secrets.Finding
+		for i, finding := range findings {
+			if !declined[findingID(finding)] && secrets.CanMove(projectPath, finding) {
+				next = &findings[i]
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		finding := *next
+
+		fmt.Printf("%s:%d: %s: %s\n", finding.File, finding.Line, secrets.Describe(finding.Rule), dotenv.MaskValue(finding.Secret))
+		move, err := ui.PromptConfirm(fmt.Sprintf("Move it to %s?", dotenv.EnvFileName(envName)))
+		if err != nil {
+			return nil, err
+		}
+		if !move {
+			declined[findingID(finding)] = true
+			continue
+		}
+
+		suggestion := suggestKey(finding)
+		if _, secret := schema.SecretExposure(suggestion); !secret {
+			suggestion = normalizeKey(suggestion, projectConfig)
+		}
+		key, err := ui.PromptEnvKey("Name of the environment variable:", suggestion)
+		if err != nil {
+			return nil, err
+		}
+		key, err = exposedKey(key, projectConfig, schema)
+		if err != nil {
+			fmt.Printf("Could not move the secret: %v\n", err)
+			declined[findingID(finding)] = true
+			continue
+		}
+
+		err = secrets.MoveToEnv(projectPath, finding, key, dotenv.EnvFilePath(projectPath, envName), projectConfig.ProjectType)
+		if err != nil {
+			fmt.Printf("Could not move the secret: %v\n", err)
+			declined[findingID(finding)] = true
+			continue
+		}
+		moved++
+
+		findings, err = secrets.Scan(projectPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if moved == 0 {
+		return findings, nil
+	}
+
+	// Keep .env and the type declarations in sync with the new keys
+	if err := syncActiveEnv(projectPath, envName, projectConfig); err != nil {
+		return nil, fmt.Errorf("failed to update .env: %v", err)
+	}
+	if err := dotenv.SyncTypes(projectPath, projectConfig.ProjectType); err != nil {
+		return nil, fmt.Errorf("failed to update type declarations: %v", err)
+	}
+
+	fmt.Printf("Moved %d %s to %s.\n", moved, pluralize(moved, "secret", "secrets"), dotenv.EnvFileName(envName))
+	if projectConfig.ProjectType == "expo" {
+		fmt.Printf("Note: %s variables are inlined into the app bundle. Keep secrets that must stay private on a server.\n", dotenv.ExpoPublicPrefix)
+	}
+	return findings, nil
+}
+
+// findingID identifies a finding across scans, independently of its position
+func findingID(finding secrets.Finding) string {
+	return finding.File + "\x00" + finding.Rule + "\x00" + finding.Secret
+}
+
+// nonKeyCharacters matches the runs of characters that cannot appear in a key
+var nonKeyCharacters = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// suggestKey proposes an env key for a finding, from the name it is assigned
+// to or from its rule
+func suggestKey(finding secrets.Finding) string {
+	name := finding.Name
+	if name == "" {
+		name = finding.Rule
+	}
+	// Split camelCase names into words
+	name = regexp.MustCompile(`([a-z0-9])([A-Z])`).ReplaceAllString(name, "${1}_${2}")
+	return strings.Trim(strings.ToUpper(nonKeyCharacters.ReplaceAllString(name, "_")), "_")
+}
+
+func init() {
+	scanSecretsCmd.Flags().Bool("json", false, "Print the findings as JSON")
+	scanSecretsCmd.Flags().Bool("fix", false, "Offer to move each secret into the env file")
+	scanSecretsCmd.Flags().String("env", "", "Environment file to move secrets into, instead of .env")
+
+	scanCmd.AddCommand(scanSecretsCmd)
+	rootCmd.AddCommand(scanCmd)
+}
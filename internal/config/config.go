@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"mirorim-cli/internal/fsutil"
 	"os"
 	"path/filepath"
 	"time"
@@ -13,6 +14,75 @@ type ProjectConfig struct {
 	ProjectType    string `json:"projectType"`
 	CreatedAt      string `json:"createdAt"`
 	EnvInitialized bool   `json:"envInitialized"`
+	ActiveEnv      string `json:"activeEnv,omitempty"`
+	SecretsKeyID   string `json:"secretsKeyId,omitempty"`
+
+	BackupRetention *BackupRetention `json:"backupRetention,omitempty"`
+	InitChanges     *InitChanges     `json:"initChanges,omitempty"`
+	DotenvPlugin    *DotenvPlugin    `json:"dotenvPlugin,omitempty"`
+}
+
+// DotenvPlugin holds the options written to the react-native-dotenv babel
+// plugin entry. Empty values leave the plugin defaults.
+type DotenvPlugin struct {
+	ModuleName string   `json:"moduleName,omitempty"`
+	Path       string   `json:"path,omitempty"`
+	Safe       bool     `json:"safe,omitempty"`
+	Allowlist  []string `json:"allowlist,omitempty"`
+	Blocklist  []string `json:"blocklist,omitempty"`
+	// GenerateAllowlist replaces Allowlist with the keys the schema does not
+	// mark as build-time-only or server-only
+	GenerateAllowlist bool `json:"generateAllowlist,omitempty"`
+}
+
+// InitChanges records what 'env init' changed outside of the env files, so
+// 'env destroy' can undo it
+type InitChanges struct {
+	ModifiedFiles     []ModifiedFile `json:"modifiedFiles,omitempty"`
+	InstalledPackages []string       `json:"installedPackages,omitempty"`
+}
+
+// ModifiedFile is a project file changed by 'env init'. Original is the copy
+// of the file before the change and SHA256 the digest of the changed content,
+// telling whether the file was edited since.
+type ModifiedFile struct {
+	Path     string `json:"path"`
+	Original string `json:"original"`
+	SHA256   string `json:"sha256"`
+}
+
+// BackupRetention limits the snapshots kept of the env files. Zero values use
+// the defaults, negative values disable a limit.
+type BackupRetention struct {
+	MaxSnapshots int `json:"maxSnapshots,omitempty"`
+	MaxAgeDays   int `json:"maxAgeDays,omitempty"`
+}
+
+// Default backup retention
+const (
+	DefaultMaxSnapshots = 20
+	DefaultMaxAgeDays   = 30
+)
+
+// Limits returns the maximum number of snapshots and their maximum age, where
+// zero means no limit. A nil retention uses the defaults.
+func (r *BackupRetention) Limits() (int, time.Duration) {
+	maxSnapshots, maxAgeDays := DefaultMaxSnapshots, DefaultMaxAgeDays
+	if r != nil {
+		if r.MaxSnapshots != 0 {
+			maxSnapshots = r.MaxSnapshots
+		}
+		if r.MaxAgeDays != 0 {
+			maxAgeDays = r.MaxAgeDays
+		}
+	}
+	if maxSnapshots < 0 {
+		maxSnapshots = 0
+	}
+	if maxAgeDays < 0 {
+		maxAgeDays = 0
+	}
+	return maxSnapshots, time.Duration(maxAgeDays) * 24 * time.Hour
 }
 
 // ConfigFileName is the name of the config file
@@ -37,6 +107,17 @@ func LoadConfig(projectPath string) (*ProjectConfig, error) {
 
 // SaveConfig saves the given ProjectConfig to the .mirorim-cli-config.json file
 func SaveConfig(projectPath string, config *ProjectConfig) error {
+	lock, err := fsutil.Lock(filepath.Join(projectPath, ConfigFileName))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return saveConfig(lock, projectPath, config)
+}
+
+// saveConfig writes the config while its lock is held
+func saveConfig(lock *fsutil.FileLock, projectPath string, config *ProjectConfig) error {
 	configPath := filepath.Join(projectPath, ConfigFileName)
 
 	// Serialize the config struct to JSON
@@ -45,8 +126,8 @@ func SaveConfig(projectPath string, config *ProjectConfig) error {
 		return fmt.Errorf("failed to serialize project config: %v", err)
 	}
 
-	// Write the config file
-	err = os.WriteFile(configPath, data, 0644)
+	// Replace the config file atomically
+	err = lock.WriteFile(data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write project config: %v", err)
 	}
@@ -65,8 +146,15 @@ func InitConfig(projectType, projectPath string) error {
 	return SaveConfig(projectPath, config)
 }
 
-// UpdateConfig updates specific fields in the existing configuration
+// UpdateConfig updates specific fields in the existing configuration. The
+// config stays locked from load to save, so concurrent updates are not lost.
 func UpdateConfig(projectPath string, updateFn func(config *ProjectConfig)) error {
+	lock, err := fsutil.Lock(filepath.Join(projectPath, ConfigFileName))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// Load the existing configuration
 	config, err := LoadConfig(projectPath)
 	if err != nil {
@@ -77,5 +165,5 @@ func UpdateConfig(projectPath string, updateFn func(config *ProjectConfig)) erro
 	updateFn(config)
 
 	// Save the updated configuration
-	return SaveConfig(projectPath, config)
+	return saveConfig(lock, projectPath, config)
 }
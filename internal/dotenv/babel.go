@@ -0,0 +1,780 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// babelConfigFiles are the babel config files looked up in the project root,
+// in order. package.json only counts when it has a "babel" key.
+var babelConfigFiles = []string{"babel.config.js", "babel.config.cjs", ".babelrc", ".babelrc.json", "package.json"}
+
+// findBabelConfig returns the name of the babel config file of the project
+func findBabelConfig(projectPath string) (string, error) {
+	for _, fileName := range babelConfigFiles {
+		data, err := os.ReadFile(filepath.Join(projectPath, fileName))
+		if err != nil {
+			continue
+		}
+		if fileName == "package.json" {
+			var manifest map[string]json.RawMessage
+			if json.Unmarshal(data, &manifest) != nil || manifest["babel"] == nil {
+				continue
+			}
+		}
+		return fileName, nil
+	}
+	return "", fmt.Errorf("babel config file not found in project root")
+}
+
+// modifyBabelConfig adds the react-native-dotenv plugin to the babel config
+// of the project, with the options of the project config
+func modifyBabelConfig(projectPath string) error {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return err
+	}
+	fileName, err := findBabelConfig(projectPath)
+	if err != nil {
+		return err
+	}
+	filePath := filepath.Join(projectPath, fileName)
+
+	babel, err := readBabelConfig(filePath)
+	if err != nil {
+		return err
+	}
+	options, err := projectPluginOptions(projectPath, cfg.DotenvPlugin)
+	if err != nil {
+		return err
+	}
+	updated, changed, err := babel.addPlugin(DotenvPackage, options)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", fileName, err)
+	}
+	if !changed {
+		fmt.Printf("react-native-dotenv plugin already present in %s\n", filePath)
+		return nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", fileName, err)
+	}
+	err = fsutil.WriteFile(filePath, []byte(updated), info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", fileName, err)
+	}
+
+	fmt.Printf("Successfully added react-native-dotenv plugin to %s\n", filePath)
+	return nil
+}
+
+// ConfigureBabelPlugin writes the options of the project config to the
+// react-native-dotenv entry of the babel config, in place, and returns the
+// name of the babel config file. Options that are not configurable are kept.
+func ConfigureBabelPlugin(projectPath string, plugin *config.DotenvPlugin) (string, bool, error) {
+	fileName, err := findBabelConfig(projectPath)
+	if err != nil {
+		return "", false, err
+	}
+	filePath := filepath.Join(projectPath, fileName)
+
+	babel, err := readBabelConfig(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	options, err := projectPluginOptions(projectPath, plugin)
+	if err != nil {
+		return "", false, err
+	}
+	updated, changed, err := babel.configurePlugin(DotenvPackage, options, configurableOptions)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to update %s: %v", fileName, err)
+	}
+	if !changed {
+		return fileName, false, nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %v", fileName, err)
+	}
+	err = fsutil.WriteFile(filePath, []byte(updated), info.Mode().Perm())
+	if err != nil {
+		return "", false, fmt.Errorf("failed to write %s: %v", fileName, err)
+	}
+	return fileName, true, nil
+}
+
+// pluginOption is an option of a babel plugin, in the order it is written
+type pluginOption struct {
+	Key   string
+	Value interface{}
+}
+
+// configurableOptions are the react-native-dotenv options set from the project config
+var configurableOptions = []string{"moduleName", "path", "safe", "allowlist", "blocklist"}
+
+// projectPluginOptions returns the options of the react-native-dotenv plugin
+// for the configured ones, with the allowlist generated from the public keys
+// of the project when it is generated (see GeneratesAllowlist)
+func projectPluginOptions(projectPath string, plugin *config.DotenvPlugin) ([]pluginOption, error) {
+	schema, err := loadProjectSchema(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if !GeneratesAllowlist(plugin, schema) {
+		return dotenvPluginOptions(plugin), nil
+	}
+
+	keys, err := PublicKeys(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	generated := config.DotenvPlugin{}
+	if plugin != nil {
+		generated = *plugin
+	}
+	generated.GenerateAllowlist = true
+	generated.Allowlist = keys
+	return dotenvPluginOptions(&generated), nil
+}
+
+// dotenvPluginOptions returns the options of the react-native-dotenv plugin
+// for the configured ones. path is only written when it is set, and a
+// generated allowlist is written even when empty so nothing is exposed.
+func dotenvPluginOptions(plugin *config.DotenvPlugin) []pluginOption {
+	if plugin == nil {
+		plugin = &config.DotenvPlugin{}
+	}
+	moduleName := plugin.ModuleName
+	if moduleName == "" {
+		moduleName = EnvModuleName
+	}
+
+	options := []pluginOption{{"moduleName", moduleName}}
+	if plugin.Path != "" {
+		options = append(options, pluginOption{"path", plugin.Path})
+	}
+	allowlist := listOption(plugin.Allowlist)
+	if plugin.GenerateAllowlist {
+		allowlist = append([]string{}, plugin.Allowlist...)
+	}
+	options = append(options,
+		pluginOption{"blocklist", listOption(plugin.Blocklist)},
+		pluginOption{"allowlist", allowlist},
+		pluginOption{"safe", plugin.Safe},
+		pluginOption{"allowUndefined", false},
+		pluginOption{"verbose", false},
+	)
+	return options
+}
+
+// listOption returns the value of a list option, null when it is empty
+func listOption(list []string) interface{} {
+	if len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// babelConfig is a babel config file broken into tokens. The file is edited
+// by splicing text at token offsets, so its formatting and comments are kept.
+type babelConfig struct {
+	name   string
+	json   bool
+	src    string
+	tokens []token
+	object int // index of the token opening the config object
+}
+
+// readBabelConfig reads and tokenizes a babel config file: babel.config.js and
+// babel.config.cjs are JavaScript, .babelrc and .babelrc.json are JSON5, and
+// package.json holds the config under its "babel" key
+func readBabelConfig(filePath string) (*babelConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+	return parseBabelConfig(filepath.Base(filePath), string(data))
+}
+
+// parseBabelConfig tokenizes the content of a babel config file and locates its config object
+func parseBabelConfig(name, src string) (*babelConfig, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+
+	c := &babelConfig{name: name, src: src, tokens: tokens, json: !strings.HasSuffix(name, ".js") && !strings.HasSuffix(name, ".cjs")}
+	if c.json {
+		c.object, err = c.jsonObject()
+	} else {
+		c.object, err = c.exportedObject()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+	return c, nil
+}
+
+// jsonObject returns the config object of a JSON file, which is the whole
+// document or the "babel" key of package.json
+func (c *babelConfig) jsonObject() (int, error) {
+	if len(c.tokens) == 0 || !c.tokens[0].is("{") {
+		return 0, fmt.Errorf("expected a JSON object")
+	}
+	if c.name != "package.json" {
+		return 0, nil
+	}
+
+	properties, err := objectProperties(c.tokens, 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, property := range properties {
+		if property.key == "babel" && c.tokens[property.value].is("{") {
+			return property.value, nil
+		}
+	}
+	return 0, fmt.Errorf(`expected an object under the "babel" key`)
+}
+
+// exportedObject returns the object exported by a JavaScript config, following
+// functions to the object they return and variables to their initializer
+func (c *babelConfig) exportedObject() (int, error) {
+	exported := -1
+	for i := range c.tokens {
+		if c.matches(i, "module", ".", "exports", "=") {
+			exported = i + 4
+		} else if c.matches(i, "export", "default") {
+			exported = i + 2
+		}
+	}
+	if exported < 0 {
+		return 0, fmt.Errorf("no module.exports or export default found")
+	}
+	return c.resolveObject(exported, 0)
+}
+
+// resolveObject returns the object literal the expression starting at token i
+// evaluates to
+func (c *babelConfig) resolveObject(i, depth int) (int, error) {
+	if i >= len(c.tokens) || depth > 10 {
+		return 0, fmt.Errorf("could not find the exported config object")
+	}
+
+	tok := c.tokens[i]
+	switch {
+	case tok.is("{"):
+		return i, nil
+	case tok.is("("):
+		// Either the parameters of an arrow function or a parenthesized expression
+		closing, err := matchingToken(c.tokens, i)
+		if err != nil {
+			return 0, err
+		}
+		if closing+1 < len(c.tokens) && c.tokens[closing+1].is("=>") {
+			return c.resolveArrowBody(closing+2, depth)
+		}
+		return c.resolveObject(i+1, depth+1)
+	case tok.is("function"), tok.is("async"):
+		// Skip to the parameters, then resolve the body
+		for j := i; j < len(c.tokens); j++ {
+			if c.tokens[j].is("(") {
+				closing, err := matchingToken(c.tokens, j)
+				if err != nil {
+					return 0, err
+				}
+				if tok.is("async") && closing+1 < len(c.tokens) && c.tokens[closing+1].is("=>") {
+					return c.resolveArrowBody(closing+2, depth)
+				}
+				return c.resolveReturn(closing+1, depth)
+			}
+		}
+	case tok.kind == identToken:
+		if i+1 < len(c.tokens) && c.tokens[i+1].is("=>") {
+			return c.resolveArrowBody(i+2, depth)
+		}
+		for j := range c.tokens {
+			if (c.tokens[j].is("const") || c.tokens[j].is("let") || c.tokens[j].is("var")) && c.matches(j+1, tok.text, "=") {
+				return c.resolveObject(j+3, depth+1)
+			}
+		}
+	}
+	return 0, fmt.Errorf("could not find the exported config object")
+}
+
+// resolveArrowBody resolves the body of an arrow function starting at token i
+func (c *babelConfig) resolveArrowBody(i, depth int) (int, error) {
+	if i < len(c.tokens) && c.tokens[i].is("{") {
+		return c.resolveReturn(i, depth)
+	}
+	return c.resolveObject(i, depth+1)
+}
+
+// resolveReturn resolves the last return statement of the function body
+// opened at token i
+func (c *babelConfig) resolveReturn(i, depth int) (int, error) {
+	if i >= len(c.tokens) || !c.tokens[i].is("{") {
+		return 0, fmt.Errorf("could not find the exported config object")
+	}
+	closing, err := matchingToken(c.tokens, i)
+	if err != nil {
+		return 0, err
+	}
+
+	last, level := -1, 0
+	for j := i + 1; j < closing; j++ {
+		switch {
+		case c.tokens[j].isOpening():
+			level++
+		case c.tokens[j].isClosing():
+			level--
+		case level == 0 && c.tokens[j].is("return"):
+			last = j
+		}
+	}
+	if last < 0 {
+		return 0, fmt.Errorf("the exported function has no return statement")
+	}
+	return c.resolveObject(last+1, depth+1)
+}
+
+// matches reports whether the tokens starting at i have the given texts
+func (c *babelConfig) matches(i int, texts ...string) bool {
+	if i+len(texts) > len(c.tokens) {
+		return false
+	}
+	for k, text := range texts {
+		if c.tokens[i+k].kind == stringToken || c.tokens[i+k].text != text {
+			return false
+		}
+	}
+	return true
+}
+
+// pluginsArray returns the index of the token opening the plugins array of
+// the config object. When the key is repeated the last one wins, as in JavaScript.
+func (c *babelConfig) pluginsArray() (int, bool, error) {
+	properties, err := objectProperties(c.tokens, c.object)
+	if err != nil {
+		return 0, false, err
+	}
+	for k := len(properties) - 1; k >= 0; k-- {
+		if properties[k].key != "plugins" {
+			continue
+		}
+		if !c.tokens[properties[k].value].is("[") {
+			return 0, false, fmt.Errorf("plugins is not an array")
+		}
+		return properties[k].value, true, nil
+	}
+	return 0, false, nil
+}
+
+// findPlugin returns the item of the plugins array configuring plugin, and
+// whether it is written as an object babel cannot read. found is false when
+// the plugin is not listed.
+func (c *babelConfig) findPlugin(array int, plugin string) (item span, invalid, found bool, err error) {
+	items, err := listItems(c.tokens, array)
+	if err != nil {
+		return span{}, false, false, err
+	}
+
+	for _, item := range items {
+		first := c.tokens[item.start]
+		switch {
+		case first.kind == stringToken && isPluginName(first.value(), plugin):
+			return item, false, true, nil
+		case first.is("["):
+			inner, err := listItems(c.tokens, item.start)
+			if err != nil {
+				return span{}, false, false, err
+			}
+			if len(inner) > 0 && c.tokens[inner[0].start].kind == stringToken && isPluginName(c.tokens[inner[0].start].value(), plugin) {
+				return item, false, true, nil
+			}
+		case first.is("{"):
+			// Older versions of the CLI wrote { "module": "react-native-dotenv", ... }
+			properties, err := objectProperties(c.tokens, item.start)
+			if err != nil {
+				return span{}, false, false, err
+			}
+			for _, property := range properties {
+				value := c.tokens[property.value]
+				if property.key == "module" && value.kind == stringToken && isPluginName(value.value(), plugin) {
+					return item, true, true, nil
+				}
+			}
+		}
+	}
+	return span{}, false, false, nil
+}
+
+// isPluginName reports whether name refers to plugin
+func isPluginName(name, plugin string) bool {
+	return name == plugin || name == "module:"+plugin
+}
+
+// addPlugin returns the content of the config with plugin listed exactly
+// once, in its ['module:<plugin>', {...}] form. changed is false when the
+// plugin was already listed.
+func (c *babelConfig) addPlugin(plugin string, options []pluginOption) (string, bool, error) {
+	array, found, err := c.pluginsArray()
+	if err != nil {
+		return "", false, err
+	}
+	unit := c.indentUnit()
+
+	if !found {
+		return c.addProperty(unit, "plugins", func(indent string, inline bool) string {
+			if inline {
+				return "[" + c.renderPlugin(plugin, options, "", "", true) + "]"
+			}
+			return c.renderList(indent, unit, c.renderPlugin(plugin, options, indent+unit, unit, false))
+		})
+	}
+
+	item, invalid, present, err := c.findPlugin(array, plugin)
+	if err != nil {
+		return "", false, err
+	}
+	if present && !invalid {
+		return c.src, false, nil
+	}
+	if invalid {
+		start, end := c.tokens[item.start].start, c.tokens[item.end-1].end
+		entry := c.renderPlugin(plugin, options, lineIndent(c.src, start), unit, false)
+		return c.src[:start] + entry + c.src[end:], true, nil
+	}
+
+	items, err := listItems(c.tokens, array)
+	if err != nil {
+		return "", false, err
+	}
+	return c.appendItem(array, items, unit, func(indent string, inline bool) string {
+		return c.renderPlugin(plugin, options, indent, unit, inline)
+	}), true, nil
+}
+
+// configurePlugin returns the content of the config with the managed options
+// of the plugin entry set to their value in options, or removed when options
+// does not have them. Other options of the entry are kept. The plugin is added
+// when it is not listed.
+func (c *babelConfig) configurePlugin(plugin string, options []pluginOption, managed []string) (string, bool, error) {
+	array, found, err := c.pluginsArray()
+	if err != nil {
+		return "", false, err
+	}
+	if !found {
+		return c.addPlugin(plugin, options)
+	}
+	item, invalid, present, err := c.findPlugin(array, plugin)
+	if err != nil {
+		return "", false, err
+	}
+	if !present || invalid {
+		return c.addPlugin(plugin, options)
+	}
+
+	// A plugin listed without options gets the whole entry
+	if _, ok, err := c.pluginOptionsObject(item); err != nil || !ok {
+		if err != nil {
+			return "", false, err
+		}
+		start, end := c.tokens[item.start].start, c.tokens[item.end-1].end
+		entry := c.renderPlugin(plugin, options, lineIndent(c.src, start), c.indentUnit(), false)
+		return c.src[:start] + entry + c.src[end:], true, nil
+	}
+
+	// Set the options one at a time, as every edit moves the tokens after it
+	src := c.src
+	for _, key := range managed {
+		current, err := parseBabelConfig(c.name, src)
+		if err != nil {
+			return "", false, err
+		}
+		array, found, err := current.pluginsArray()
+		if err != nil {
+			return "", false, err
+		}
+		item, invalid, present, err := current.findPlugin(array, plugin)
+		if err != nil {
+			return "", false, err
+		}
+		object, ok, err := current.pluginOptionsObject(item)
+		if err != nil {
+			return "", false, err
+		}
+		if !found || invalid || !present || !ok {
+			return "", false, fmt.Errorf("lost the options of %s in %s while editing them", plugin, c.name)
+		}
+
+		var value interface{}
+		set := false
+		for _, option := range options {
+			if option.Key == key {
+				value, set = option.Value, true
+			}
+		}
+		if src, err = current.setOption(object, key, value, set); err != nil {
+			return "", false, err
+		}
+	}
+	return src, src != c.src, nil
+}
+
+// pluginOptionsObject returns the index of the token opening the options
+// object of a ['module:<plugin>', {...}] entry
+func (c *babelConfig) pluginOptionsObject(item span) (int, bool, error) {
+	if !c.tokens[item.start].is("[") {
+		return 0, false, nil
+	}
+	inner, err := listItems(c.tokens, item.start)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(inner) < 2 || !c.tokens[inner[1].start].is("{") {
+		return 0, false, nil
+	}
+	return inner[1].start, true, nil
+}
+
+// setOption returns the content of the config with the property key of the
+// object opened at token object set to value, or removed when set is false
+func (c *babelConfig) setOption(object int, key string, value interface{}, set bool) (string, error) {
+	properties, err := objectProperties(c.tokens, object)
+	if err != nil {
+		return "", err
+	}
+	for _, property := range properties {
+		if property.key != key {
+			continue
+		}
+		start, end := c.tokens[property.start].start, c.tokens[property.end-1].end
+		if !set {
+			return removeListEntry(c.src, start, end), nil
+		}
+		valueStart := c.tokens[property.value].start
+		return c.src[:valueStart] + c.renderValue(value) + c.src[end:], nil
+	}
+	if !set {
+		return c.src, nil
+	}
+
+	name := key
+	if c.json {
+		name = strconv.Quote(key)
+	}
+	items := make([]span, len(properties))
+	for k, property := range properties {
+		items[k] = property.span
+	}
+	return c.appendItem(object, items, c.indentUnit(), func(string, bool) string {
+		return name + ": " + c.renderValue(value)
+	}), nil
+}
+
+// removePlugin returns the content of the config without plugin, and whether
+// it was listed
+func (c *babelConfig) removePlugin(plugin string) (string, bool, error) {
+	array, found, err := c.pluginsArray()
+	if err != nil || !found {
+		return c.src, false, err
+	}
+	item, _, present, err := c.findPlugin(array, plugin)
+	if err != nil || !present {
+		return c.src, false, err
+	}
+
+	// Leave an empty array rather than a blank line between its brackets
+	items, err := listItems(c.tokens, array)
+	if err != nil {
+		return c.src, false, err
+	}
+	if len(items) == 1 {
+		closing, err := matchingToken(c.tokens, array)
+		if err != nil {
+			return c.src, false, err
+		}
+		return c.src[:c.tokens[array].end] + c.src[c.tokens[closing].start:], true, nil
+	}
+	return removeListEntry(c.src, c.tokens[item.start].start, c.tokens[item.end-1].end), true, nil
+}
+
+// addProperty returns the content of the config with a new property appended
+// to the config object. render returns the value for the indentation of the
+// key, on a single line when inline is set.
+func (c *babelConfig) addProperty(unit, key string, render func(indent string, inline bool) string) (string, bool, error) {
+	properties, err := objectProperties(c.tokens, c.object)
+	if err != nil {
+		return "", false, err
+	}
+	if c.json {
+		key = strconv.Quote(key)
+	}
+
+	items := make([]span, len(properties))
+	for k, property := range properties {
+		items[k] = property.span
+	}
+	return c.appendItem(c.object, items, unit, func(indent string, inline bool) string {
+		return key + ": " + render(indent, inline)
+	}), true, nil
+}
+
+// appendItem returns the content of the config with an item appended to the
+// array or object opened at token open, following its layout: one item per
+// line, all on a single line, or empty
+func (c *babelConfig) appendItem(open int, items []span, unit string, render func(indent string, inline bool) string) string {
+	closing, _ := matchingToken(c.tokens, open)
+	openEnd, closeStart := c.tokens[open].end, c.tokens[closing].start
+
+	if len(items) == 0 {
+		base := lineIndent(c.src, c.tokens[open].start)
+		text := "\n" + base + unit + render(base+unit, false) + c.trailingComma() + "\n" + base
+		if strings.TrimSpace(c.src[openEnd:closeStart]) == "" {
+			return c.src[:openEnd] + text + c.src[closeStart:]
+		}
+		return c.src[:openEnd] + text + c.src[openEnd:]
+	}
+
+	last := c.tokens[items[len(items)-1].end-1].end
+	trailing := c.tokens[closing-1].is(",")
+	if !strings.Contains(c.src[openEnd:c.tokens[items[0].start].start], "\n") {
+		return c.src[:last] + ", " + render("", true) + c.src[last:]
+	}
+
+	indent := lineIndent(c.src, c.tokens[items[0].start].start)
+	if trailing {
+		comma := c.tokens[closing-1].end
+		return c.src[:comma] + "\n" + indent + render(indent, false) + "," + c.src[comma:]
+	}
+	return c.src[:last] + ",\n" + indent + render(indent, false) + c.src[last:]
+}
+
+// trailingComma returns the comma ending the last line of a new multi-line
+// list, which JSON does not allow
+func (c *babelConfig) trailingComma() string {
+	if c.json {
+		return ""
+	}
+	return ","
+}
+
+// renderList renders a multi-line array holding a single item
+func (c *babelConfig) renderList(indent, unit, item string) string {
+	return "[\n" + indent + unit + item + c.trailingComma() + "\n" + indent + "]"
+}
+
+// renderPlugin renders a ['module:<plugin>', {...}] plugin entry whose
+// options are indented one level deeper than indent, or on a single line
+// when inline is set
+func (c *babelConfig) renderPlugin(plugin string, options []pluginOption, indent, unit string, inline bool) string {
+	fields := make([]string, len(options))
+	for k, option := range options {
+		key := option.Key
+		if c.json {
+			key = strconv.Quote(key)
+		}
+		fields[k] = key + ": " + c.renderValue(option.Value)
+	}
+
+	name := c.quote("module:" + plugin)
+	if inline {
+		return "[" + name + ", { " + strings.Join(fields, ", ") + " }]"
+	}
+	separator := ",\n" + indent + unit
+	return "[" + name + ", {\n" + indent + unit + strings.Join(fields, separator) + c.trailingComma() + "\n" + indent + "}]"
+}
+
+// renderValue renders an option value as a JavaScript or JSON literal
+func (c *babelConfig) renderValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return c.quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for k, item := range v {
+			quoted[k] = c.quote(item)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	return fmt.Sprint(value)
+}
+
+// quote renders a string literal, using the quote style of the file
+func (c *babelConfig) quote(s string) string {
+	if c.json {
+		return strconv.Quote(s)
+	}
+	quote := "'"
+	for _, tok := range c.tokens {
+		if tok.kind == stringToken {
+			quote = tok.text[:1]
+			break
+		}
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return quote + strings.ReplaceAll(s, quote, `\`+quote) + quote
+}
+
+// indentUnit returns the indentation of one level in the file, from the
+// properties of the config object
+func (c *babelConfig) indentUnit() string {
+	properties, err := objectProperties(c.tokens, c.object)
+	if err == nil && len(properties) > 0 {
+		outer := lineIndent(c.src, c.tokens[c.object].start)
+		inner := lineIndent(c.src, c.tokens[properties[0].start].start)
+		if len(inner) > len(outer) && strings.HasPrefix(inner, outer) && strings.Contains(c.src[c.tokens[c.object].end:c.tokens[properties[0].start].start], "\n") {
+			return inner[len(outer):]
+		}
+	}
+	return "  "
+}
+
+// lineIndent returns the leading whitespace of the line holding offset
+func lineIndent(src string, offset int) string {
+	start := strings.LastIndexByte(src[:offset], '\n') + 1
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[start:end]
+}
+
+// removeListEntry removes content[start:stop] along with its separating comma,
+// and the whole lines it spans when nothing else is on them
+func removeListEntry(content string, start, stop int) string {
+	next := stop
+	for next < len(content) && strings.ContainsRune(" \t", rune(content[next])) {
+		next++
+	}
+	if next < len(content) && content[next] == ',' {
+		stop = next + 1
+	} else {
+		previous := strings.TrimRight(content[:start], " \t\r\n")
+		if strings.HasSuffix(previous, ",") {
+			start = len(previous) - 1
+		}
+	}
+
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := strings.IndexByte(content[stop:], '\n')
+	if lineEnd >= 0 && strings.TrimSpace(content[lineStart:start]) == "" && strings.TrimSpace(content[stop:stop+lineEnd]) == "" {
+		start, stop = lineStart, stop+lineEnd+1
+	}
+	return content[:start] + content[stop:]
+}
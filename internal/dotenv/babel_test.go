@@ -0,0 +1,254 @@
+package dotenv
+
+import (
+	"mirorim-cli/internal/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testOptions keeps the rendered plugin entries short
+var testOptions = []pluginOption{{"moduleName", "@env"}, {"safe", false}}
+
+func TestAddPlugin(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		src     string
+		want    string
+		changed bool
+	}{
+		{
+			"module.exports object",
+			"babel.config.js",
+			"module.exports = {\n  presets: ['module:metro-react-native-babel-preset'],\n};\n",
+			"module.exports = {\n  presets: ['module:metro-react-native-babel-preset'],\n  plugins: [\n    ['module:react-native-dotenv', {\n      moduleName: '@env',\n      safe: false,\n    }],\n  ],\n};\n",
+			true,
+		},
+		{
+			"function",
+			"babel.config.js",
+			"module.exports = function (api) {\n  api.cache(true);\n  return {\n    presets: [\"babel-preset-expo\"],\n    plugins: [\n      \"react-native-reanimated/plugin\"\n    ]\n  };\n};\n",
+			"module.exports = function (api) {\n  api.cache(true);\n  return {\n    presets: [\"babel-preset-expo\"],\n    plugins: [\n      \"react-native-reanimated/plugin\",\n      [\"module:react-native-dotenv\", {\n        moduleName: \"@env\",\n        safe: false,\n      }]\n    ]\n  };\n};\n",
+			true,
+		},
+		{
+			"arrow with single-line array",
+			"babel.config.cjs",
+			"module.exports = (api) => ({ presets: ['babel-preset-expo'], plugins: ['a', 'b'] });\n",
+			"module.exports = (api) => ({ presets: ['babel-preset-expo'], plugins: ['a', 'b', ['module:react-native-dotenv', { moduleName: '@env', safe: false }]] });\n",
+			true,
+		},
+		{
+			"export default with empty array",
+			"babel.config.js",
+			"export default {\n  plugins: [],\n};\n",
+			"export default {\n  plugins: [\n    ['module:react-native-dotenv', {\n      moduleName: '@env',\n      safe: false,\n    }],\n  ],\n};\n",
+			true,
+		},
+		{
+			"variable",
+			"babel.config.js",
+			"const config = { presets: [] };\nmodule.exports = config;\n",
+			"const config = { presets: [], plugins: [['module:react-native-dotenv', { moduleName: '@env', safe: false }]] };\nmodule.exports = config;\n",
+			true,
+		},
+		{
+			".babelrc",
+			".babelrc",
+			"{\n  // JSON5 comments are kept\n  \"presets\": [\"module:metro-react-native-babel-preset\"],\n  \"plugins\": [\n    \"a\"\n  ]\n}\n",
+			"{\n  // JSON5 comments are kept\n  \"presets\": [\"module:metro-react-native-babel-preset\"],\n  \"plugins\": [\n    \"a\",\n    [\"module:react-native-dotenv\", {\n      \"moduleName\": \"@env\",\n      \"safe\": false\n    }]\n  ]\n}\n",
+			true,
+		},
+		{
+			"package.json",
+			"package.json",
+			"{\n  \"name\": \"app\",\n  \"babel\": {\n    \"presets\": [\"module:metro-react-native-babel-preset\"]\n  }\n}\n",
+			"{\n  \"name\": \"app\",\n  \"babel\": {\n    \"presets\": [\"module:metro-react-native-babel-preset\"],\n    \"plugins\": [\n      [\"module:react-native-dotenv\", {\n        \"moduleName\": \"@env\",\n        \"safe\": false\n      }]\n    ]\n  }\n}\n",
+			true,
+		},
+		{
+			"already listed",
+			"babel.config.js",
+			"module.exports = { plugins: [['module:react-native-dotenv', { moduleName: '@vars' }]] };\n",
+			"module.exports = { plugins: [['module:react-native-dotenv', { moduleName: '@vars' }]] };\n",
+			false,
+		},
+		{
+			"legacy object entry",
+			"babel.config.js",
+			"module.exports = {\n  plugins: [\n    { module: 'react-native-dotenv', moduleName: '@env' },\n  ],\n};\n",
+			"module.exports = {\n  plugins: [\n    ['module:react-native-dotenv', {\n      moduleName: '@env',\n      safe: false,\n    }],\n  ],\n};\n",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseBabelConfig(tt.file, tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, changed, err := c.addPlugin(DotenvPackage, testOptions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || changed != tt.changed {
+				t.Errorf("addPlugin() = %q, %v\nwant %q, %v", got, changed, tt.want, tt.changed)
+			}
+			if _, err := parseBabelConfig(tt.file, got); err != nil {
+				t.Errorf("the result does not parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemovePlugin(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    string
+		removed bool
+	}{
+		{
+			"only plugin",
+			"module.exports = {\n  plugins: [\n    ['module:react-native-dotenv', {\n      moduleName: '@env',\n    }],\n  ],\n};\n",
+			"module.exports = {\n  plugins: [],\n};\n",
+			true,
+		},
+		{
+			"among others",
+			"module.exports = {\n  plugins: [\n    'a',\n    'module:react-native-dotenv',\n    'b',\n  ],\n};\n",
+			"module.exports = {\n  plugins: [\n    'a',\n    'b',\n  ],\n};\n",
+			true,
+		},
+		{
+			"last on a single line",
+			"module.exports = { plugins: ['a', ['react-native-dotenv', {}]] };\n",
+			"module.exports = { plugins: ['a'] };\n",
+			true,
+		},
+		{
+			"not listed",
+			"module.exports = { plugins: ['a'] };\n",
+			"module.exports = { plugins: ['a'] };\n",
+			false,
+		},
+		{
+			"no plugins",
+			"module.exports = {};\n",
+			"module.exports = {};\n",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseBabelConfig("babel.config.js", tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, removed, err := c.removePlugin(DotenvPackage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || removed != tt.removed {
+				t.Errorf("removePlugin() = %q, %v\nwant %q, %v", got, removed, tt.want, tt.removed)
+			}
+		})
+	}
+}
+
+func TestConfigurePlugin(t *testing.T) {
+	src := "module.exports = {\n  plugins: [\n    ['module:react-native-dotenv', {\n      moduleName: '@env',\n      path: '.env.local',\n      custom: 1,\n    }],\n  ],\n};\n"
+	c, err := parseBabelConfig("babel.config.js", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, changed, err := c.configurePlugin(DotenvPackage, []pluginOption{{"moduleName", "@vars"}, {"safe", true}}, []string{"moduleName", "path", "safe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "module.exports = {\n  plugins: [\n    ['module:react-native-dotenv', {\n      moduleName: '@vars',\n      custom: 1,\n      safe: true,\n    }],\n  ],\n};\n"
+	if got != want || !changed {
+		t.Errorf("configurePlugin() = %q, %v\nwant %q, true", got, changed, want)
+	}
+}
+
+func TestParseBabelConfigErrors(t *testing.T) {
+	tests := []struct {
+		file string
+		src  string
+	}{
+		{"babel.config.js", "const config = {};\n"},
+		{"babel.config.js", "module.exports = function () {};\n"},
+		{".babelrc", "[]"},
+		{"package.json", "{ \"name\": \"app\" }"},
+	}
+
+	for _, tt := range tests {
+		if _, err := parseBabelConfig(tt.file, tt.src); err == nil {
+			t.Errorf("parseBabelConfig(%s, %q) succeeded", tt.file, tt.src)
+		}
+	}
+}
+
+func TestAddPluginUnbalancedBrackets(t *testing.T) {
+	c, err := parseBabelConfig("babel.config.js", "module.exports = { plugins: ['a' };\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.addPlugin(DotenvPackage, testOptions); err == nil {
+		t.Error("addPlugin() succeeded on unbalanced brackets")
+	}
+}
+
+func TestProjectPluginOptionsAllowlist(t *testing.T) {
+	project := t.TempDir()
+	err := os.WriteFile(filepath.Join(project, ".env"), []byte("API_URL=1\nSERVER_TOKEN=2\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowlist := func(plugin *config.DotenvPlugin) interface{} {
+		options, err := projectPluginOptions(project, plugin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, option := range options {
+			if option.Key == "allowlist" {
+				return option.Value
+			}
+		}
+		t.Fatal("no allowlist option")
+		return nil
+	}
+
+	// Without secret keys nothing is filtered
+	if got := allowlist(nil); got != nil {
+		t.Errorf("allowlist without schema = %v, want null", got)
+	}
+
+	schema := &Schema{Variables: map[string]*VariableSchema{
+		"SERVER_TOKEN": {Type: TypeString, Exposure: ExposureServer},
+	}}
+	if err := SaveSchema(project, schema); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		plugin *config.DotenvPlugin
+		want   interface{}
+	}{
+		{"no options", nil, []string{"API_URL"}},
+		{"other options", &config.DotenvPlugin{Safe: true}, []string{"API_URL"}},
+		{"own allowlist", &config.DotenvPlugin{Allowlist: []string{"SERVER_TOKEN"}}, []string{"SERVER_TOKEN"}},
+		{"blocklist", &config.DotenvPlugin{Blocklist: []string{"API_URL"}}, nil},
+	}
+	for _, tt := range tests {
+		if got := allowlist(tt.plugin); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: allowlist = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
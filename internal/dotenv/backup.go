@@ -0,0 +1,266 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BackupDir is the directory, relative to the project, holding the snapshots
+// taken before env files are changed or deleted
+var BackupDir = filepath.Join(".mirorim-cli", "backups")
+
+// manifestFileName describes the content of a snapshot directory
+const manifestFileName = "snapshot.json"
+
+// snapshotIDFormat names snapshots after the UTC time they were taken
+const snapshotIDFormat = "20060102T150405.000Z"
+
+// Snapshot is a copy of env files taken before an operation changed them
+type Snapshot struct {
+	ID        string         `json:"id"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Operation string         `json:"operation"`
+	Files     []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is a single file of a snapshot
+type SnapshotFile struct {
+	Name      string `json:"name"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+}
+
+// snapshotBeforeWrite takes a snapshot of an env file that is about to be
+// replaced with content. Nothing is kept when the content does not change, for
+// files generated by 'env use', and outside of mirorim-cli projects.
+func snapshotBeforeWrite(path, content, operation string) error {
+	current, err := os.ReadFile(path)
+	if err != nil || string(current) == content {
+		return nil
+	}
+	_, err = CreateSnapshot(filepath.Dir(path), operation, []string{filepath.Base(path)})
+	return err
+}
+
+// CreateSnapshot copies the given env files of the project into a new
+// snapshot, encrypted with the project key when one is available, and then
+// applies the retention policy. Missing and generated files are skipped; nil
+// is returned when nothing had to be kept.
+func CreateSnapshot(projectPath, operation string, names []string) (*Snapshot, error) {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		// Not a mirorim-cli project
+		return nil, nil
+	}
+
+	var sources []string
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(projectPath, name))
+		if err != nil || strings.HasPrefix(string(data), materializedHeaderPrefix) {
+			continue
+		}
+		sources = append(sources, name)
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	snapshot := &Snapshot{ID: now.Format(snapshotIDFormat), CreatedAt: now, Operation: operation}
+	dir := filepath.Join(projectPath, BackupDir, snapshot.ID)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		snapshot.ID = fmt.Sprintf("%s-%d", now.Format(snapshotIDFormat), i)
+		dir = filepath.Join(projectPath, BackupDir, snapshot.ID)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	encrypt := hasProjectKey(cfg.SecretsKeyID)
+	for _, name := range sources {
+		file, err := backupFile(projectPath, dir, name, cfg.SecretsKeyID, encrypt)
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, err
+		}
+		snapshot.Files = append(snapshot.Files, file)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize snapshot: %v", err)
+	}
+	if err := fsutil.WriteFile(filepath.Join(dir, manifestFileName), append(data, '\n'), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %v", err)
+	}
+
+	if err := PruneSnapshots(projectPath, cfg.BackupRetention); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// backupFile copies one env file into a snapshot directory, encrypting its
+// values when possible. Files that do not parse are copied as they are.
+func backupFile(projectPath, dir, name, keyID string, encrypt bool) (SnapshotFile, error) {
+	path := filepath.Join(projectPath, name)
+	if encrypt {
+		if envFile, err := ReadEnvFile(path); err == nil {
+			err = EncryptEnvFile(envFile, filepath.Join(dir, name+EncryptedSuffix), keyID, SecretsKey{})
+			if err != nil {
+				return SnapshotFile{}, fmt.Errorf("failed to back up %s: %v", name, err)
+			}
+			return SnapshotFile{Name: name, Encrypted: true}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SnapshotFile{}, fmt.Errorf("failed to back up %s: %v", name, err)
+	}
+	if err := fsutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+		return SnapshotFile{}, fmt.Errorf("failed to back up %s: %v", name, err)
+	}
+	return SnapshotFile{Name: name}, nil
+}
+
+// hasProjectKey reports whether the project key can be used without asking,
+// from MIRORIM_ENV_KEY or from an existing project key file
+func hasProjectKey(keyID string) bool {
+	if keyID == "" {
+		return false
+	}
+	if os.Getenv(KeyEnvVar) != "" {
+		return true
+	}
+	keyFile, err := DefaultKeyFile(keyID)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(keyFile)
+	return err == nil
+}
+
+// ListSnapshots returns the snapshots of the project, newest first
+func ListSnapshots(projectPath string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(projectPath, BackupDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %v", err)
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectPath, BackupDir, entry.Name(), manifestFileName))
+		if err != nil {
+			continue
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot %s: %v", entry.Name(), err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// FindSnapshot returns the snapshot with the given ID, or the latest one when
+// id is empty
+func FindSnapshot(projectPath, id string) (*Snapshot, error) {
+	snapshots, err := ListSnapshots(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("there are no snapshots")
+	}
+	if id == "" {
+		return &snapshots[0], nil
+	}
+	for i := range snapshots {
+		if snapshots[i].ID == id {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot %s does not exist, run 'env history' to list them", id)
+}
+
+// RestoreSnapshot writes the files of a snapshot back into the project. The
+// current files are snapshotted first, so a restore can be undone as well.
+func RestoreSnapshot(projectPath string, snapshot *Snapshot, secretsKey SecretsKey) error {
+	dir := filepath.Join(projectPath, BackupDir, snapshot.ID)
+
+	// Decrypt everything before touching the project
+	contents := make(map[string]string)
+	for _, file := range snapshot.Files {
+		if !file.Encrypted {
+			data, err := os.ReadFile(filepath.Join(dir, file.Name))
+			if err != nil {
+				return fmt.Errorf("failed to read %s from the snapshot: %v", file.Name, err)
+			}
+			contents[file.Name] = string(data)
+			continue
+		}
+
+		envFile, err := DecryptEnvFile(filepath.Join(dir, file.Name+EncryptedSuffix), "", secretsKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s from the snapshot: %v", file.Name, err)
+		}
+		contents[file.Name] = envFile.Render()
+	}
+
+	names := make([]string, 0, len(snapshot.Files))
+	for _, file := range snapshot.Files {
+		names = append(names, file.Name)
+	}
+	if _, err := CreateSnapshot(projectPath, "restore "+snapshot.ID, names); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		err := fsutil.WriteFile(filepath.Join(projectPath, name), []byte(contents[name]), 0600)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// PruneSnapshots deletes the snapshots exceeding the retention policy
+func PruneSnapshots(projectPath string, retention *config.BackupRetention) error {
+	maxSnapshots, maxAge := retention.Limits()
+
+	snapshots, err := ListSnapshots(projectPath)
+	if err != nil {
+		return err
+	}
+	for i, snapshot := range snapshots {
+		tooMany := maxSnapshots > 0 && i >= maxSnapshots
+		tooOld := maxAge > 0 && time.Since(snapshot.CreatedAt) > maxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(projectPath, BackupDir, snapshot.ID)); err != nil {
+			return fmt.Errorf("failed to delete snapshot %s: %v", snapshot.ID, err)
+		}
+	}
+	return nil
+}
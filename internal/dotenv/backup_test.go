@@ -0,0 +1,218 @@
+package dotenv
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mirorim-cli/internal/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newBackupProject creates a mirorim-cli project holding .env with content
+func newBackupProject(t *testing.T, cfg *config.ProjectConfig, content string) string {
+	setTestHome(t)
+	project := t.TempDir()
+	if err := config.SaveConfig(project, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".env"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+// updateTestEnv sets a key of .env through SaveEnvFile
+func updateTestEnv(t *testing.T, project, key, value string) {
+	envFile, err := LoadEnvFile(filepath.Join(project, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envFile.AddOrUpdateKey(key, value)
+	if err := envFile.SaveEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotBeforeWrite(t *testing.T) {
+	project := newBackupProject(t, &config.ProjectConfig{ProjectType: "bare", EnvInitialized: true}, "API_KEY=old\n")
+
+	updateTestEnv(t, project, "API_KEY", "new")
+	snapshots, err := ListSnapshots(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 {
+		t.Fatalf("ListSnapshots() = %+v, want one snapshot", snapshots)
+	}
+	snapshot := snapshots[0]
+	if snapshot.Operation != "update" || !reflect.DeepEqual(snapshot.Files, []SnapshotFile{{Name: ".env"}}) {
+		t.Errorf("snapshot = %+v, want an unencrypted .env taken by update", snapshot)
+	}
+	data, err := os.ReadFile(filepath.Join(project, BackupDir, snapshot.ID, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "API_KEY=old\n" {
+		t.Errorf("snapshot holds %q, want the content before the write", data)
+	}
+
+	// Writing the same content keeps nothing
+	updateTestEnv(t, project, "API_KEY", "new")
+	if snapshots, _ := ListSnapshots(project); len(snapshots) != 1 {
+		t.Errorf("unchanged write took a snapshot: %+v", snapshots)
+	}
+
+	// Files generated by 'env use' are not kept
+	generated := materializedHeaderPrefix + ".env.staging.\nAPI_KEY=staging\n"
+	if err := os.WriteFile(filepath.Join(project, ".env"), []byte(generated), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceEnvFile(filepath.Join(project, ".env"), "API_KEY=edited\n"); err != nil {
+		t.Fatal(err)
+	}
+	if snapshots, _ := ListSnapshots(project); len(snapshots) != 1 {
+		t.Errorf("overwriting a generated file took a snapshot: %+v", snapshots)
+	}
+}
+
+func TestSnapshotOutsideOfProjects(t *testing.T) {
+	setTestHome(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceEnvFile(path, "A=2\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, BackupDir)); !os.IsNotExist(err) {
+		t.Errorf("a snapshot was taken outside of a mirorim-cli project")
+	}
+}
+
+// writeTestSnapshot writes the manifest of a snapshot taken at createdAt
+func writeTestSnapshot(t *testing.T, project string, createdAt time.Time) string {
+	snapshot := Snapshot{ID: createdAt.UTC().Format(snapshotIDFormat), CreatedAt: createdAt, Operation: "update"}
+	dir := filepath.Join(project, BackupDir, snapshot.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestFileName), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return snapshot.ID
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		retention *config.BackupRetention
+		kept      int // newest snapshots kept out of the four
+	}{
+		{"defaults", nil, 3},
+		{"count", &config.BackupRetention{MaxSnapshots: 2, MaxAgeDays: -1}, 2},
+		{"age", &config.BackupRetention{MaxSnapshots: -1, MaxAgeDays: 7}, 3},
+		{"unlimited", &config.BackupRetention{MaxSnapshots: -1, MaxAgeDays: -1}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			var ids []string
+			for _, age := range []time.Duration{0, time.Hour, 48 * time.Hour, 40 * 24 * time.Hour} {
+				ids = append(ids, writeTestSnapshot(t, project, now.Add(-age)))
+			}
+
+			if err := PruneSnapshots(project, tt.retention); err != nil {
+				t.Fatal(err)
+			}
+			snapshots, err := ListSnapshots(project)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, snapshot := range snapshots {
+				got = append(got, snapshot.ID)
+			}
+			if !reflect.DeepEqual(got, ids[:tt.kept]) {
+				t.Errorf("kept %v, want %v", got, ids[:tt.kept])
+			}
+		})
+	}
+}
+
+func TestCreateSnapshotAppliesRetention(t *testing.T) {
+	cfg := &config.ProjectConfig{ProjectType: "bare", EnvInitialized: true, BackupRetention: &config.BackupRetention{MaxSnapshots: 2}}
+	project := newBackupProject(t, cfg, "A=0\n")
+
+	for _, value := range []string{"1", "2", "3"} {
+		updateTestEnv(t, project, "A", value)
+	}
+	snapshots, err := ListSnapshots(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("ListSnapshots() = %+v, want two snapshots", snapshots)
+	}
+	// The newest snapshot holds the content before the last write
+	data, err := os.ReadFile(filepath.Join(project, BackupDir, snapshots[0].ID, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "A=2\n" {
+		t.Errorf("latest snapshot holds %q, want %q", data, "A=2\n")
+	}
+}
+
+func TestRestoreSnapshotRoundTrip(t *testing.T) {
+	cfg := &config.ProjectConfig{ProjectType: "bare", EnvInitialized: true, SecretsKeyID: "0123456789abcdef"}
+	project := newBackupProject(t, cfg, "API_KEY=sk_live_old\n")
+	t.Setenv(KeyEnvVar, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, keySize)))
+
+	updateTestEnv(t, project, "API_KEY", "sk_live_new")
+	snapshot, err := FindSnapshot(project, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshot.Files, []SnapshotFile{{Name: ".env", Encrypted: true}}) {
+		t.Fatalf("snapshot files = %+v, want an encrypted .env", snapshot.Files)
+	}
+	encrypted, err := os.ReadFile(filepath.Join(project, BackupDir, snapshot.ID, ".env"+EncryptedSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(encrypted, []byte("sk_live_old")) {
+		t.Errorf("snapshot holds the value in plain text:\n%s", encrypted)
+	}
+
+	if err := RestoreSnapshot(project, snapshot, SecretsKey{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(project, ".env")); string(data) != "API_KEY=sk_live_old\n" {
+		t.Errorf(".env = %q after restoring, want the old value", data)
+	}
+
+	// The restore is snapshotted too, so it can be undone
+	undo, err := FindSnapshot(project, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if undo.Operation != "restore "+snapshot.ID {
+		t.Fatalf("latest snapshot is %q, want the one taken by the restore", undo.Operation)
+	}
+	if err := RestoreSnapshot(project, undo, SecretsKey{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(project, ".env")); string(data) != "API_KEY=sk_live_new\n" {
+		t.Errorf(".env = %q after undoing the restore, want the new value", data)
+	}
+}
@@ -0,0 +1,471 @@
+package dotenv
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// EncryptedSuffix is appended to an env file name for its encrypted counterpart
+const EncryptedSuffix = ".enc"
+
+// KeyEnvVar holds a base64 encoded key, for CI where no key file is available
+const KeyEnvVar = "MIRORIM_ENV_KEY"
+
+// PassphraseEnvVar holds the passphrase to derive the key from
+const PassphraseEnvVar = "MIRORIM_ENV_PASSPHRASE"
+
+const (
+	encryptedValuePrefix = "enc:v1:"
+	encryptedHeader      = "# mirorim-cli encrypted env file. Values are encrypted, it is safe to commit.\n# Decrypt it with \"mirorim-cli env decrypt\"."
+	keyHeaderPrefix      = "# mirorim-cli:"
+	keySize              = 32
+	pbkdf2Iterations     = 210000
+)
+
+// SecretsKey describes where the encryption key comes from. A passphrase takes
+// precedence over a key file; without either, the key is read from the
+// MIRORIM_ENV_KEY variable or from the project key file in the user's home.
+type SecretsKey struct {
+	KeyFile    string
+	Passphrase string
+}
+
+// EncryptedFilePath returns the path of the encrypted file of an environment
+func EncryptedFilePath(projectPath, env string) string {
+	return EnvFilePath(projectPath, env) + EncryptedSuffix
+}
+
+// DefaultKeyFile returns the location of a project key outside the repository
+func DefaultKeyFile(keyID string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %v", err)
+	}
+	return filepath.Join(home, ".mirorim-cli", "keys", keyID+".key"), nil
+}
+
+// NewKeyID returns a random identifier naming a project key file
+func NewKeyID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate key id: %v", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// keyParams are the key derivation settings stored in the header of an encrypted file
+type keyParams struct {
+	keyID      string
+	salt       []byte
+	iterations int
+}
+
+// header renders the parameters as a comment line
+func (p keyParams) header() string {
+	if p.salt != nil {
+		return fmt.Sprintf("%skdf=pbkdf2-sha256 salt=%s iterations=%d", keyHeaderPrefix, base64.StdEncoding.EncodeToString(p.salt), p.iterations)
+	}
+	return fmt.Sprintf("%skey=%s", keyHeaderPrefix, p.keyID)
+}
+
+// parseKeyParams reads the parameters from the header line of an encrypted file
+func parseKeyParams(line string) (keyParams, error) {
+	var params keyParams
+	for _, field := range strings.Fields(strings.TrimPrefix(line, keyHeaderPrefix)) {
+		name, value, _ := strings.Cut(field, "=")
+		switch name {
+		case "key":
+			params.keyID = value
+		case "salt":
+			salt, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return params, fmt.Errorf("invalid salt in header: %v", err)
+			}
+			params.salt = salt
+		case "iterations":
+			iterations, err := strconv.Atoi(value)
+			if err != nil {
+				return params, fmt.Errorf("invalid iterations in header: %v", err)
+			}
+			params.iterations = iterations
+		}
+	}
+	return params, nil
+}
+
+// resolveKey returns the encryption key. With create, a missing default key
+// file is generated so the first encryption works out of the box. Without it,
+// a missing key file is reported along with how to get the key.
+func (k SecretsKey) resolveKey(params keyParams, create bool) ([]byte, error) {
+	if k.Passphrase != "" {
+		if params.salt == nil {
+			return nil, fmt.Errorf("the encrypted file was not encrypted with a passphrase")
+		}
+		return pbkdf2.Key([]byte(k.Passphrase), params.salt, params.iterations, keySize, sha256.New), nil
+	}
+	if params.salt != nil {
+		return nil, fmt.Errorf("the encrypted file requires a passphrase")
+	}
+
+	if k.KeyFile == "" {
+		if encoded := os.Getenv(KeyEnvVar); encoded != "" {
+			return decodeKey(encoded, KeyEnvVar)
+		}
+	}
+
+	keyFile := k.KeyFile
+	if keyFile == "" {
+		if params.keyID == "" {
+			return nil, fmt.Errorf("no key file given and the encrypted file does not name a project key")
+		}
+		var err error
+		keyFile, err = DefaultKeyFile(params.keyID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if os.IsNotExist(err) && create {
+		return generateKeyFile(keyFile)
+	}
+	if os.IsNotExist(err) && k.KeyFile == "" {
+		return nil, fmt.Errorf("the project key %s is not in %s; get it from a teammate, or pass --key-file or set %s", params.keyID, keyFile, KeyEnvVar)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %v", keyFile, err)
+	}
+	return decodeKey(strings.TrimSpace(string(data)), keyFile)
+}
+
+// decodeKey decodes a base64 encoded 256-bit key
+func decodeKey(encoded, source string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("%s does not hold a base64 encoded %d-byte key", source, keySize)
+	}
+	return key, nil
+}
+
+// generateKeyFile creates a new random key readable only by the current user
+func generateKeyFile(keyFile string) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %v", err)
+	}
+	err := fsutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write key file: %v", err)
+	}
+
+	fmt.Printf("Generated a new project key in %s. Share it with your team over a secure channel.\n", keyFile)
+	return key, nil
+}
+
+// encryptValue encrypts a value with AES-GCM. The key name is authenticated
+// so encrypted values cannot be swapped between keys.
+func encryptValue(gcm cipher.AEAD, name, value string) (string, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+	return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptValue reverses encryptValue
+func decryptValue(gcm cipher.AEAD, name, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedValuePrefix) {
+		return "", fmt.Errorf("%s is not encrypted", name)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("%s has a malformed encrypted value", name)
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: wrong key or tampered value", name)
+	}
+	return string(plain), nil
+}
+
+// newGCM creates the AES-GCM cipher for a key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptedFile is a parsed encrypted env file
+type encryptedFile struct {
+	params keyParams
+	env    *DotenvFile
+}
+
+// readEncryptedFile reads an encrypted env file, splitting off its header
+func readEncryptedFile(path string) (*encryptedFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
+	}
+
+	var params keyParams
+	var body []string
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		switch {
+		case strings.HasPrefix(line, keyHeaderPrefix):
+			params, err = parseKeyParams(strings.TrimSpace(line))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+			}
+		case isEncryptedHeaderLine(strings.TrimRight(line, "\n")):
+			// The explanation lines are regenerated on every save
+		default:
+			body = append(body, line)
+		}
+	}
+
+	env, err := Parse(strings.Join(body, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
+	}
+	env.Path = path
+	return &encryptedFile{params: params, env: env}, nil
+}
+
+// isEncryptedHeaderLine reports whether line is one of the explanation lines written by EncryptEnvFile
+func isEncryptedHeaderLine(line string) bool {
+	for _, headerLine := range strings.Split(encryptedHeader, "\n") {
+		if line == headerLine {
+			return true
+		}
+	}
+	return false
+}
+
+// commentAuthData is added to the authenticated data of encrypted comments,
+// so a comment cannot pass for a value
+const commentAuthData = "#"
+
+// isEncryptedComment reports whether a line is a full-line comment encrypted by EncryptEnvFile
+func (l *envLine) isEncryptedComment() bool {
+	return l.isComment() && strings.HasPrefix(strings.TrimSpace(l.raw), "#"+encryptedValuePrefix)
+}
+
+// encryptComment encrypts a comment, the text of a full-line comment or an
+// inline comment of the variable name, reusing its previous ciphertext when
+// it did not change
+func encryptComment(gcm cipher.AEAD, name, text string, previous map[string]string) (string, error) {
+	if old, ok := previous[name+commentAuthData+text]; ok {
+		return old, nil
+	}
+	return encryptValue(gcm, name+commentAuthData, text)
+}
+
+// encryptedComments maps the comments of an encrypted file, prefixed with the
+// variable they belong to, to their ciphertext
+func encryptedComments(gcm cipher.AEAD, env *DotenvFile) (map[string]string, error) {
+	comments := make(map[string]string)
+	for _, line := range env.lines {
+		name, encrypted := line.key, line.comment
+		if line.isEncryptedComment() {
+			encrypted = strings.TrimPrefix(strings.TrimSpace(line.raw), "#")
+		}
+		if !strings.HasPrefix(encrypted, encryptedValuePrefix) {
+			continue
+		}
+		plain, err := decryptValue(gcm, name+commentAuthData, encrypted)
+		if err != nil {
+			return nil, err
+		}
+		comments[name+commentAuthData+plain] = encrypted
+	}
+	return comments, nil
+}
+
+// keyReferenced reports whether an encrypted env file in dir was encrypted with
+// the project key keyID
+func keyReferenced(dir, keyID string) bool {
+	paths, _ := filepath.Glob(filepath.Join(dir, ".env*"+EncryptedSuffix))
+	for _, path := range paths {
+		if encrypted, err := readEncryptedFile(path); err == nil && encrypted.params.keyID == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesPassphrase reports whether an existing encrypted file was encrypted with
+// a key derived from a passphrase
+func UsesPassphrase(encPath string) bool {
+	encrypted, err := readEncryptedFile(encPath)
+	return err == nil && encrypted.params.salt != nil
+}
+
+// EncryptEnvFile writes the encrypted counterpart of an env file. Keys, blank
+// lines, section headers and order stay readable while each value is
+// encrypted on its own, so the file can be committed and reviewed key by key.
+// Other comments are encrypted too, as they may hold values.
+// Values and comments that did not change keep their previous ciphertext to
+// keep diffs small. A project key file is only generated when no encrypted
+// file uses the key yet.
+func EncryptEnvFile(source *DotenvFile, encPath, keyID string, secretsKey SecretsKey) error {
+	params := keyParams{keyID: keyID}
+	var previous *encryptedFile
+	if _, err := os.Stat(encPath); err == nil {
+		previous, err = readEncryptedFile(encPath)
+		if err != nil {
+			return err
+		}
+		params = previous.params
+	}
+
+	// Switching between passphrase and key file starts from fresh parameters
+	if secretsKey.Passphrase != "" && params.salt == nil {
+		params = keyParams{salt: make([]byte, 16), iterations: pbkdf2Iterations}
+		if _, err := rand.Read(params.salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %v", err)
+		}
+		previous = nil
+	} else if secretsKey.Passphrase == "" && params.salt != nil {
+		params = keyParams{keyID: keyID}
+		previous = nil
+	}
+
+	key, err := secretsKey.resolveKey(params, !keyReferenced(filepath.Dir(encPath), params.keyID))
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return fmt.Errorf("failed to initialize cipher: %v", err)
+	}
+
+	previousComments := make(map[string]string)
+	if previous != nil {
+		previousComments, err = encryptedComments(gcm, previous.env)
+		if err != nil {
+			return fmt.Errorf("%v; the existing %s was encrypted with a different key", err, filepath.Base(encPath))
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(encryptedHeader + "\n" + params.header() + "\n")
+	headers := source.sectionHeaders()
+	for i, line := range source.lines {
+		if line.isBlank() || headers[i] {
+			content.WriteString(line.raw + "\n")
+			continue
+		}
+		if !line.isVariable() {
+			encrypted, err := encryptComment(gcm, "", line.raw, previousComments)
+			if err != nil {
+				return err
+			}
+			content.WriteString("#" + encrypted + "\n")
+			continue
+		}
+
+		encrypted := ""
+		if previous != nil {
+			if old, ok := previous.env.Variables[line.key]; ok {
+				plain, err := decryptValue(gcm, line.key, old)
+				if err != nil {
+					return fmt.Errorf("%v; the existing %s was encrypted with a different key", err, filepath.Base(encPath))
+				}
+				if plain == line.value {
+					encrypted = old
+				}
+			}
+		}
+		if encrypted == "" {
+			encrypted, err = encryptValue(gcm, line.key, line.value)
+			if err != nil {
+				return err
+			}
+		}
+
+		encLine := &envLine{export: line.export, key: line.key, value: encrypted, modified: true}
+		if line.comment != "" {
+			encLine.comment, err = encryptComment(gcm, line.key, line.comment, previousComments)
+			if err != nil {
+				return err
+			}
+		}
+		content.WriteString(encLine.render() + "\n")
+	}
+
+	err = fsutil.WriteFile(encPath, []byte(content.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(encPath), err)
+	}
+	return nil
+}
+
+// DecryptEnvFile decrypts an encrypted env file into an env file bound to
+// targetPath, keeping its comments and order
+func DecryptEnvFile(encPath, targetPath string, secretsKey SecretsKey) (*DotenvFile, error) {
+	encrypted, err := readEncryptedFile(encPath)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := secretsKey.resolveKey(encrypted.params, false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cipher: %v", err)
+	}
+
+	decrypted := &DotenvFile{Path: targetPath, Variables: make(map[string]string)}
+	for _, line := range encrypted.env.lines {
+		if line.isEncryptedComment() {
+			raw, err := decryptValue(gcm, commentAuthData, strings.TrimPrefix(strings.TrimSpace(line.raw), "#"))
+			if err != nil {
+				return nil, err
+			}
+			decrypted.lines = append(decrypted.lines, &envLine{raw: raw})
+			continue
+		}
+		if !line.isVariable() {
+			decrypted.lines = append(decrypted.lines, &envLine{raw: line.raw})
+			continue
+		}
+
+		plain, err := decryptValue(gcm, line.key, line.value)
+		if err != nil {
+			return nil, err
+		}
+		comment := line.comment
+		if strings.HasPrefix(comment, encryptedValuePrefix) {
+			comment, err = decryptValue(gcm, line.key+commentAuthData, comment)
+			if err != nil {
+				return nil, err
+			}
+		}
+		decrypted.lines = append(decrypted.lines, &envLine{export: line.export, key: line.key, value: plain, comment: comment, modified: true})
+		decrypted.Variables[line.key] = plain
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,193 @@
+package dotenv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const secretContent = `# API
+API_URL=https://api.example.com # prod: https://internal.example.com
+# API_KEY=sk_live_0123456789abcdef
+# token sk_live_0123456789abcdef
+API_KEY="sk_live_abcdef0123456789"
+
+export MULTI="line 1
+line 2"
+# old password hunter2
+`
+
+// setTestHome points the default key files to a temporary home directory
+func setTestHome(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(KeyEnvVar, "")
+	return home
+}
+
+func TestPassphraseKeyDerivation(t *testing.T) {
+	// PBKDF2-HMAC-SHA256 test vectors of RFC 7914, section 11
+	tests := []struct {
+		passphrase string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+	}
+
+	for _, tt := range tests {
+		params := keyParams{salt: []byte(tt.salt), iterations: tt.iterations}
+		key, err := SecretsKey{Passphrase: tt.passphrase}.resolveKey(params, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key); got != tt.want {
+			t.Errorf("key for %q = %s, want %s", tt.passphrase, got, tt.want)
+		}
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	setTestHome(t)
+	for _, secretsKey := range []SecretsKey{
+		{},
+		{KeyFile: filepath.Join(t.TempDir(), "project.key")},
+		{Passphrase: "correct horse battery staple"},
+	} {
+		dir := t.TempDir()
+		source, err := Parse(secretContent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encPath := filepath.Join(dir, ".env"+EncryptedSuffix)
+		if err := EncryptEnvFile(source, encPath, "0123456789abcdef", secretsKey); err != nil {
+			t.Fatal(err)
+		}
+
+		encrypted, err := os.ReadFile(encPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, plain := range []string{"sk_live", "internal.example.com", "api.example.com", "line 1", "hunter2"} {
+			if bytes.Contains(encrypted, []byte(plain)) {
+				t.Errorf("%s holds %q in plain text:\n%s", encPath, plain, encrypted)
+			}
+		}
+		for _, readable := range []string{"# API\n", "API_URL=enc:v1:", "export MULTI=enc:v1:"} {
+			if !bytes.Contains(encrypted, []byte(readable)) {
+				t.Errorf("%s does not hold %q:\n%s", encPath, readable, encrypted)
+			}
+		}
+
+		decrypted, err := DecryptEnvFile(encPath, filepath.Join(dir, ".env"), secretsKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Values are written back in their canonical quoting
+		want := strings.NewReplacer(`"sk_live_abcdef0123456789"`, "sk_live_abcdef0123456789", "line 1\nline 2", `line 1\nline 2`).Replace(secretContent)
+		if got := decrypted.Render(); got != want {
+			t.Errorf("decrypted content = %q, want %q", got, want)
+		}
+
+		// Encrypting the same content again keeps every ciphertext
+		if err := EncryptEnvFile(source, encPath, "0123456789abcdef", secretsKey); err != nil {
+			t.Fatal(err)
+		}
+		again, err := os.ReadFile(encPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again, encrypted) {
+			t.Errorf("re-encrypting unchanged content changed %s:\n%s\n---\n%s", encPath, encrypted, again)
+		}
+	}
+}
+
+func TestDecryptWithWrongKey(t *testing.T) {
+	setTestHome(t)
+	dir := t.TempDir()
+	source, err := Parse("KEY=value\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encPath := filepath.Join(dir, ".env"+EncryptedSuffix)
+	if err := EncryptEnvFile(source, encPath, "", SecretsKey{Passphrase: "right"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecryptEnvFile(encPath, "", SecretsKey{Passphrase: "wrong"}); err == nil {
+		t.Error("decrypting with a wrong passphrase succeeded")
+	}
+	if _, err := DecryptEnvFile(encPath, "", SecretsKey{}); err == nil {
+		t.Error("decrypting a passphrase file without the passphrase succeeded")
+	}
+}
+
+func TestEncryptValuesAreBoundToTheirKey(t *testing.T) {
+	setTestHome(t)
+	dir := t.TempDir()
+	source, err := Parse("A=first\nB=second\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encPath := filepath.Join(dir, ".env"+EncryptedSuffix)
+	if err := EncryptEnvFile(source, encPath, "0123456789abcdef", SecretsKey{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the two ciphertexts
+	encrypted, err := readEncryptedFile(encPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(encPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := encrypted.env.Variables["A"], encrypted.env.Variables["B"]
+	swapped := strings.NewReplacer("A="+a, "A="+b, "B="+b, "B="+a).Replace(string(content))
+	if err := os.WriteFile(encPath, []byte(swapped), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecryptEnvFile(encPath, "", SecretsKey{}); err == nil {
+		t.Error("decrypting swapped values succeeded")
+	}
+}
+
+func TestEncryptDoesNotReplaceAMissingProjectKey(t *testing.T) {
+	home := setTestHome(t)
+	dir := t.TempDir()
+	source, err := Parse("KEY=value\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first encryption generates the project key
+	keyID := "0123456789abcdef"
+	if err := EncryptEnvFile(source, filepath.Join(dir, ".env"+EncryptedSuffix), keyID, SecretsKey{}); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(home, ".mirorim-cli", "keys", keyID+".key")
+	if _, err := os.Stat(keyFile); err != nil {
+		t.Fatalf("project key was not generated: %v", err)
+	}
+
+	// Without the key, another environment of the project cannot be encrypted
+	if err := os.Remove(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	err = EncryptEnvFile(source, filepath.Join(dir, ".env.staging"+EncryptedSuffix), keyID, SecretsKey{})
+	if err == nil || !strings.Contains(err.Error(), keyID) {
+		t.Errorf("EncryptEnvFile() error = %v, want a missing project key error", err)
+	}
+	if _, err := os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Errorf("a new project key was generated for %s", keyID)
+	}
+}
@@ -0,0 +1,78 @@
+package dotenv
+
+import (
+	"sort"
+	"strings"
+)
+
+// Kinds of differences reported by DiffVariables
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffEntry describes a key that differs between two env files
+type DiffEntry struct {
+	Key      string `json:"key"`
+	Kind     string `json:"kind"`
+	OldValue string `json:"old,omitempty"`
+	NewValue string `json:"new,omitempty"`
+}
+
+// DiffVariables compares the variables of two env files and returns the keys
+// added in b, removed from a and changed between them, sorted by key. In Expo
+// projects public keys are compared with the EXPO_PUBLIC_ prefix applied, so
+// API_URL and EXPO_PUBLIC_API_URL are the same variable. Keys the schema marks
+// as build-time-only or server-only are compared as written.
+func DiffVariables(a, b map[string]string, projectType string, schema *Schema) []DiffEntry {
+	oldVars := normalizeKeys(a, projectType, schema)
+	newVars := normalizeKeys(b, projectType, schema)
+
+	var entries []DiffEntry
+	for key, oldValue := range oldVars {
+		newValue, ok := newVars[key]
+		switch {
+		case !ok:
+			entries = append(entries, DiffEntry{Key: key, Kind: DiffRemoved, OldValue: oldValue})
+		case newValue != oldValue:
+			entries = append(entries, DiffEntry{Key: key, Kind: DiffChanged, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, newValue := range newVars {
+		if _, ok := oldVars[key]; !ok {
+			entries = append(entries, DiffEntry{Key: key, Kind: DiffAdded, NewValue: newValue})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// normalizeKeys returns the variables with the keys the CLI would store them
+// under. An explicitly prefixed key wins over its unprefixed form, and secret
+// keys keep their unprefixed form.
+func normalizeKeys(vars map[string]string, projectType string, schema *Schema) map[string]string {
+	if projectType != "expo" {
+		return vars
+	}
+
+	normalized := make(map[string]string, len(vars))
+	for key, value := range vars {
+		if strings.HasPrefix(key, ExpoPublicPrefix) {
+			normalized[key] = value
+		}
+	}
+	for key, value := range vars {
+		prefixed := key
+		if _, secret := schema.SecretExposure(key); !secret {
+			prefixed = EnsureExpoPrefix(key)
+		}
+		if _, ok := normalized[prefixed]; !ok {
+			normalized[prefixed] = value
+		}
+	}
+	return normalized
+}
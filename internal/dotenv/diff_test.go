@@ -0,0 +1,55 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffVariables(t *testing.T) {
+	schema := &Schema{Variables: map[string]*VariableSchema{
+		"SERVER_TOKEN": {Type: TypeString, Exposure: ExposureServer},
+	}}
+
+	tests := []struct {
+		name        string
+		a, b        map[string]string
+		projectType string
+		want        []DiffEntry
+	}{
+		{
+			name:        "bare keys as written",
+			a:           map[string]string{"API_URL": "a", "OLD": "x"},
+			b:           map[string]string{"API_URL": "b", "EXPO_PUBLIC_OLD": "x"},
+			projectType: "bare",
+			want: []DiffEntry{
+				{Key: "API_URL", Kind: DiffChanged, OldValue: "a", NewValue: "b"},
+				{Key: "EXPO_PUBLIC_OLD", Kind: DiffAdded, NewValue: "x"},
+				{Key: "OLD", Kind: DiffRemoved, OldValue: "x"},
+			},
+		},
+		{
+			name:        "expo public keys with the prefix applied",
+			a:           map[string]string{"API_URL": "a", "EXPO_PUBLIC_DEBUG": "true"},
+			b:           map[string]string{"EXPO_PUBLIC_API_URL": "a", "DEBUG": "false", "EXPO_PUBLIC_DEBUG": "true"},
+			projectType: "expo",
+		},
+		{
+			name:        "expo secret keys as written",
+			a:           map[string]string{"SERVER_TOKEN": "a"},
+			b:           map[string]string{"SERVER_TOKEN": "b", "EXPO_PUBLIC_SERVER_TOKEN": "a"},
+			projectType: "expo",
+			want: []DiffEntry{
+				{Key: "EXPO_PUBLIC_SERVER_TOKEN", Kind: DiffAdded, NewValue: "a"},
+				{Key: "SERVER_TOKEN", Kind: DiffChanged, OldValue: "a", NewValue: "b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffVariables(tt.a, tt.b, tt.projectType, schema)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffVariables() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,110 +1,265 @@
 package dotenv
 
 import (
-	"bufio"
-	"encoding/json"
 	"fmt"
 	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/fsutil"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 )
 
-// DotenvFile represents the .env file operations
+// DotenvFile represents the .env file operations. Besides the key/value
+// lookup map it keeps every line of the file in its original order so that
+// comments, blank lines and untouched variables survive a save unchanged.
 type DotenvFile struct {
 	Path      string
 	Variables map[string]string
+	lines     []*envLine
+}
+
+// envLine is a single line of a .env file. Lines that have not been modified
+// are written back using raw, so their formatting is preserved byte for byte.
+type envLine struct {
+	raw      string
+	export   bool
+	key      string // empty for blank and comment lines
+	value    string
+	comment  string // inline comment without the leading '#'
+	expand   bool   // whether references in the value are interpolated
+	template string // value with escaped dollars marked as literalDollar
+	modified bool
+}
+
+// isVariable reports whether the line holds a key/value pair
+func (l *envLine) isVariable() bool {
+	return l.key != ""
+}
+
+// isComment reports whether the line is a full-line comment
+func (l *envLine) isComment() bool {
+	return l.key == "" && strings.HasPrefix(strings.TrimSpace(l.raw), "#")
+}
+
+// isBlank reports whether the line is empty or only whitespace
+func (l *envLine) isBlank() bool {
+	return l.key == "" && strings.TrimSpace(l.raw) == ""
+}
+
+// render returns the text that should be written for the line
+func (l *envLine) render() string {
+	if !l.modified {
+		return l.raw
+	}
+	line := fmt.Sprintf("%s=%s", l.key, FormatValue(l.value))
+	if l.export {
+		line = "export " + line
+	}
+	if l.comment != "" {
+		line += " #" + l.comment
+	}
+	return line
 }
 
 // EnsureExpoPrefix ensures that Expo keys start with EXPO_PUBLIC_
 func EnsureExpoPrefix(key string) string {
-	if !strings.HasPrefix(key, "EXPO_PUBLIC_") {
-		return "EXPO_PUBLIC_" + key
+	if !strings.HasPrefix(key, ExpoPublicPrefix) {
+		return ExpoPublicPrefix + key
 	}
 	return key
 }
 
 // LoadEnvFile reads the .env file into memory
 func LoadEnvFile(path string) (*DotenvFile, error) {
-	envFile := &DotenvFile{
-		Path:      path,
-		Variables: make(map[string]string),
-	}
-
 	// Check if .env file exists, if not create it
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		file, err := os.Create(path)
+		err := fsutil.WriteFile(path, nil, 0644)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create .env file: %v", err)
 		}
-		defer file.Close()
 	}
 
-	// Read the .env file line by line
-	file, err := os.Open(path)
+	return ReadEnvFile(path)
+}
+
+// ReadEnvFile reads an existing .env file into memory without creating it
+func ReadEnvFile(path string) (*DotenvFile, error) {
+	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open .env file: %v", err)
+		return nil, fmt.Errorf("failed to read %s: %v", filepath.Base(path), err)
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		// Skip comments and empty lines
-		if strings.HasPrefix(line, "#") || line == "" {
-			continue
-		}
 
-		// Split key and value
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		envFile.Variables[key] = value
+	// Parse the content while keeping every line for round-tripping
+	envFile, err := Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filepath.Base(path), err)
 	}
+	envFile.Path = path
 
 	return envFile, nil
 }
 
-// SaveEnvFile writes the environment variables back to the .env file
-func (e *DotenvFile) SaveEnvFile() error {
-	file, err := os.Create(e.Path)
+// UpdateEnvFile loads the env file at path, creating it when missing, applies
+// updateFn and saves the result. The file stays locked from load to save, so
+// concurrent updates are not lost. Nothing is written when updateFn fails.
+func UpdateEnvFile(path string, updateFn func(envFile *DotenvFile) error) error {
+	lock, err := fsutil.Lock(path)
 	if err != nil {
-		return fmt.Errorf("failed to open .env file for writing: %v", err)
+		return err
 	}
-	defer file.Close()
+	defer lock.Unlock()
 
-	// Write each key-value pair to the file
-	for key, value := range e.Variables {
-		_, err := file.WriteString(fmt.Sprintf("%s=%s\n", key, value))
+	envFile := &DotenvFile{Path: path, Variables: make(map[string]string)}
+	if _, err := os.Stat(path); err == nil {
+		envFile, err = ReadEnvFile(path)
 		if err != nil {
-			return fmt.Errorf("failed to write key-value pair to .env: %v", err)
+			return err
 		}
 	}
 
+	if err := updateFn(envFile); err != nil {
+		return err
+	}
+	return envFile.save(lock)
+}
+
+// SaveEnvFile writes the environment variables back to the .env file. The
+// file is replaced atomically, so a crash never leaves it half written.
+func (e *DotenvFile) SaveEnvFile() error {
+	lock, err := fsutil.Lock(e.Path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return e.save(lock)
+}
+
+// save writes the env file while its lock is held
+func (e *DotenvFile) save(lock *fsutil.FileLock) error {
+	// Keep the previous content so the change can be undone
+	content := e.Render()
+	if err := snapshotBeforeWrite(e.Path, content, "update"); err != nil {
+		return err
+	}
+
+	// Write each line back in its original order
+	err := lock.WriteFile([]byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(e.Path), err)
+	}
 	return nil
 }
 
-// AddOrUpdateKey adds or updates a key in the .env file (ensures UPPER CASE for keys)
+// Render returns the content of the env file as SaveEnvFile writes it
+func (e *DotenvFile) Render() string {
+	var content strings.Builder
+	for _, line := range e.lines {
+		content.WriteString(line.render() + "\n")
+	}
+	return content.String()
+}
+
+// AddOrUpdateKey adds or updates a key in the .env file (ensures UPPER CASE for new keys).
+// Existing keys are matched as written and updated in place, new keys are appended to the end of the file.
 func (e *DotenvFile) AddOrUpdateKey(key, value string) {
-	key = strings.ToUpper(key) // Ensure the key is UPPER CASE
+	e.AddOrUpdateKeyInSection(key, value, "")
+}
+
+// AddOrUpdateKeyInSection adds or updates a key like AddOrUpdateKey, but places
+// new keys at the end of the named section. A section is a full-line comment
+// (e.g. "# API") followed by variables; it is created at the end of the file
+// if it does not exist yet. An empty section appends to the end of the file.
+func (e *DotenvFile) AddOrUpdateKeyInSection(key, value, section string) {
+	if _, exists := e.Variables[key]; !exists {
+		key = strings.ToUpper(key) // Ensure new keys are UPPER CASE
+	}
 	e.Variables[key] = value
+
+	// Update the last occurrence in place, the one that wins when loading
+	for i := len(e.lines) - 1; i >= 0; i-- {
+		if e.lines[i].key == key {
+			// Values written by the CLI are quoted literally and never interpolated
+			e.lines[i].value = value
+			e.lines[i].expand = false
+			e.lines[i].modified = true
+			return
+		}
+	}
+
+	line := &envLine{key: key, value: value, modified: true}
+	if section == "" {
+		e.lines = append(e.lines, line)
+		return
+	}
+
+	index := e.sectionEnd(section)
+	if index < 0 {
+		// Separate the new section from existing content with a blank line
+		if len(e.lines) > 0 && !e.lines[len(e.lines)-1].isBlank() {
+			e.lines = append(e.lines, &envLine{})
+		}
+		e.lines = append(e.lines, &envLine{raw: "# " + section}, line)
+		return
+	}
+
+	e.lines = append(e.lines[:index], append([]*envLine{line}, e.lines[index:]...)...)
 }
 
-// RemoveKey removes a key from the .env file
+// sectionEnd returns the index right after the last variable of the named
+// section, or -1 if no comment line names the section
+func (e *DotenvFile) sectionEnd(section string) int {
+	header := -1
+	for i, line := range e.lines {
+		if line.isComment() && strings.EqualFold(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line.raw), "#")), section) {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return -1
+	}
+
+	// The section continues until the first blank or comment line after its variables
+	end := header + 1
+	for i := header + 1; i < len(e.lines); i++ {
+		if e.lines[i].isVariable() {
+			end = i + 1
+			continue
+		}
+		if end > header+1 || e.lines[i].isBlank() {
+			break
+		}
+	}
+	return end
+}
+
+// RemoveKey removes a key from the .env file. Like in AddOrUpdateKey, the key
+// is matched as written, and upper-cased when no such key is defined.
 func (e *DotenvFile) RemoveKey(key string) {
+	if _, exists := e.Variables[key]; !exists {
+		key = strings.ToUpper(key)
+	}
 	delete(e.Variables, key)
+
+	lines := e.lines[:0]
+	for _, line := range e.lines {
+		if line.key != key {
+			lines = append(lines, line)
+		}
+	}
+	e.lines = lines
 }
 
-// ListKeys returns a list of keys currently present in the .env file
+// ListKeys returns a list of keys currently present in the .env file, in file order
 func (e *DotenvFile) ListKeys() []string {
 	keys := make([]string, 0, len(e.Variables))
-	for key := range e.Variables {
-		keys = append(keys, key)
+	seen := make(map[string]bool, len(e.Variables))
+	for _, line := range e.lines {
+		if line.isVariable() && !seen[line.key] {
+			seen[line.key] = true
+			keys = append(keys, line.key)
+		}
 	}
 	return keys
 }
@@ -134,144 +289,75 @@ func CreateEnvFiles(projectPath, projectType string) error {
 	// Create .env file if it doesn't exist
 	envFilePath := filepath.Join(projectPath, ".env")
 	if _, err := os.Stat(envFilePath); os.IsNotExist(err) {
-		_, err := os.Create(envFilePath)
+		err := fsutil.WriteFile(envFilePath, nil, 0644)
 		if err != nil {
 			return fmt.Errorf("failed to create .env file: %v", err)
 		}
 	}
 
-	// If Bare, set up react-native-dotenv and env.d.ts
+	// Keep the env files out of git
+	added, err := EnsureGitignore(projectPath)
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		fmt.Printf("Added %s to .gitignore\n", strings.Join(added, ", "))
+	}
+
+	// If Bare, set up react-native-dotenv and env.d.ts, recording the changes
+	// so 'env destroy' can undo them
 	if projectType == "bare" {
+		installed := packageDependsOn(projectPath, DotenvPackage)
 		err := installReactNativeDotenv(projectPath)
 		if err != nil {
 			return err
 		}
-
-		err = modifyBabelConfig(projectPath)
-		if err != nil {
-			return err
+		if !installed {
+			err = recordInstalledPackage(projectPath, DotenvPackage)
+			if err != nil {
+				return err
+			}
 		}
 
-		// Create env.d.ts
-		err = UpdateEnvDTS(projectPath, "", "", false)
+		babelConfig, err := findBabelConfig(projectPath)
 		if err != nil {
-			return fmt.Errorf("failed to create env.d.ts: %v", err)
+			return err
 		}
-	}
-
-	// Mark environment as initialized
-	err := MarkEnvInitialized(projectPath)
-	if err != nil {
-		return err
-	}
-
-	fmt.Println("Environment successfully initialized.")
-	return nil
-}
-
-// EnsureCorrectEnvDTSStructure ensures the env.d.ts file is properly initialized
-func EnsureCorrectEnvDTSStructure(envPath string) error {
-	envDTSPath := filepath.Join(envPath, "env.d.ts")
-
-	// If the file does not exist, create it with the correct structure
-	if _, err := os.Stat(envDTSPath); os.IsNotExist(err) {
-		file, err := os.Create(envDTSPath)
+		err = recordModification(projectPath, babelConfig, func() error {
+			return modifyBabelConfig(projectPath)
+		})
 		if err != nil {
-			return fmt.Errorf("failed to create env.d.ts file: %v", err)
+			return err
 		}
-		defer file.Close()
 
-		// Write the initial structure
-		_, err = file.WriteString(`declare module "@env" {
-}
-`)
+		// Create env.d.ts
+		err = SyncEnvDTS(projectPath)
 		if err != nil {
-			return fmt.Errorf("failed to initialize env.d.ts: %v", err)
+			return fmt.Errorf("failed to create env.d.ts: %v", err)
 		}
 	}
 
-	// Check if the file is malformed or missing the correct structure
-	content, err := os.ReadFile(envDTSPath)
-	if err != nil {
-		return fmt.Errorf("failed to read env.d.ts file: %v", err)
-	}
-
-	if !strings.Contains(string(content), `declare module "@env" {`) {
-		// The file is corrupted or improperly formatted, rewrite it
-		err := os.WriteFile(envDTSPath, []byte(`declare module "@env" {
-}
-`), 0644)
+	// If Expo, create the typed src/config/env.ts accessor module
+	if projectType == "expo" {
+		err := SyncExpoEnvModule(projectPath)
 		if err != nil {
-			return fmt.Errorf("failed to reset env.d.ts structure: %v", err)
+			return fmt.Errorf("failed to create %s: %v", ExpoEnvModulePath, err)
 		}
 	}
 
-	return nil
-}
-
-// UpdateEnvDTS updates the env.d.ts file with new or updated environment variables
-func UpdateEnvDTS(envPath string, key, value string, isRemove bool) error {
-	envDTSPath := filepath.Join(envPath, "env.d.ts")
-
-	// Ensure the file is correctly initialized
-	err := EnsureCorrectEnvDTSStructure(envPath)
+	// Mark environment as initialized
+	err = MarkEnvInitialized(projectPath)
 	if err != nil {
 		return err
 	}
 
-	// Read the content of the file into memory
-	file, err := os.OpenFile(envDTSPath, os.O_RDWR, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open env.d.ts: %v", err)
-	}
-	defer file.Close()
-
-	var content []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		content = append(content, scanner.Text())
-	}
-
-	// Process adding or removing keys
-	if isRemove {
-		// Remove the key
-		for i, line := range content {
-			if strings.Contains(line, fmt.Sprintf("export const %s", key)) {
-				content = append(content[:i], content[i+1:]...)
-				break
-			}
-		}
-	} else {
-		// Add or update the key
-		found := false
-		for i, line := range content {
-			if strings.Contains(line, fmt.Sprintf("export const %s", key)) {
-				// Update the existing key
-				content[i] = fmt.Sprintf("  export const %s: string;", strings.ToUpper(key))
-				found = true
-				break
-			}
-		}
-		if !found {
-			// Insert the new key before the closing brace
-			if key != "" {
-				content = append(content[:len(content)-1], fmt.Sprintf("  export const %s: string;", strings.ToUpper(key)), "}")
-			}
-		}
-	}
-
-	// Write the updated content back to the file
-	err = os.WriteFile(envDTSPath, []byte(strings.Join(content, "\n")), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write updated env.d.ts file: %v", err)
-	}
-
+	fmt.Println("Environment successfully initialized.")
 	return nil
 }
 
 // Install react-native-dotenv for Bare React Native projects
 func installReactNativeDotenv(projectPath string) error {
-	cmd := exec.Command("npm", "install", "--save-dev", "react-native-dotenv")
+	cmd := exec.Command("npm", "install", "--save-dev", DotenvPackage)
 	cmd.Dir = projectPath
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -283,157 +369,6 @@ func installReactNativeDotenv(projectPath string) error {
 	return nil
 }
 
-// modifyBabelConfig modifies babel.config.js or .babelrc to include the react-native-dotenv plugin
-func modifyBabelConfig(projectPath string) error {
-	configFilePaths := []string{"babel.config.js", ".babelrc"}
-
-	for _, fileName := range configFilePaths {
-		filePath := filepath.Join(projectPath, fileName)
-
-		if _, err := os.Stat(filePath); err == nil {
-			if strings.HasSuffix(fileName, ".js") {
-				return modifyBabelJSConfig(filePath)
-			} else if strings.HasSuffix(fileName, ".babelrc") {
-				return modifyBabelJSONConfig(filePath)
-			}
-		}
-	}
-	return fmt.Errorf("babel config file not found in project root")
-}
-
-// modifyBabelJSConfig modifies babel.config.js to include the react-native-dotenv plugin
-func modifyBabelJSConfig(filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read %s: %v", filePath, err)
-	}
-
-	pluginConfig := `
-  ['module:react-native-dotenv', {
-    moduleName: '@env',
-    blocklist: null,
-    allowlist: null,
-    safe: false,
-    allowUndefined: false,
-    verbose: false,
-  }],
-`
-
-	// Check if the file already contains react-native-dotenv plugin
-	if strings.Contains(string(content), "react-native-dotenv") {
-		fmt.Printf("react-native-dotenv plugin already present in %s\n", filePath)
-		return nil
-	}
-
-	// Handle the case where babel.config.js is a function returning an object
-	if strings.Contains(string(content), "module.exports = function") {
-		return modifyBabelFunctionJSConfig(filePath, string(content), pluginConfig)
-	}
-
-	// Handle the case where babel.config.js directly exports an object
-	return modifyBabelObjectJSConfig(filePath, string(content), pluginConfig)
-}
-
-// modifyBabelFunctionJSConfig modifies a babel.config.js function to add the react-native-dotenv plugin
-func modifyBabelFunctionJSConfig(filePath, content, pluginConfig string) error {
-	// We need to locate the return statement and modify the object returned by the function
-	regex := regexp.MustCompile(`(return\s*\{)`)
-	updatedContent := regex.ReplaceAllString(content, "$1\n  plugins: ["+pluginConfig+"],")
-	err := os.WriteFile(filePath, []byte(updatedContent), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to update function-based babel.config.js: %v", err)
-	}
-
-	fmt.Printf("Successfully added react-native-dotenv plugin to %s\n", filePath)
-	return nil
-}
-
-// modifyBabelObjectJSConfig modifies a babel.config.js that exports an object directly
-func modifyBabelObjectJSConfig(filePath, content, pluginConfig string) error {
-	// If plugins array does not exist, add it after the presets key if it exists
-	if !strings.Contains(content, "plugins") {
-		if strings.Contains(content, "presets") {
-			// Insert plugins after presets
-			regex := regexp.MustCompile(`(presets:\s*\[.*?\],)`)
-			updatedContent := regex.ReplaceAllString(content, "$1\n  plugins: ["+pluginConfig+"],")
-			return os.WriteFile(filePath, []byte(updatedContent), 0644)
-		} else {
-			// If no presets, just add plugins at the top level
-			regex := regexp.MustCompile(`(module\.exports\s*=\s*\{)`)
-			updatedContent := regex.ReplaceAllString(content, "$1\n  plugins: ["+pluginConfig+"],")
-			return os.WriteFile(filePath, []byte(updatedContent), 0644)
-		}
-	}
-
-	// If plugins array exists, append the plugin
-	regex := regexp.MustCompile(`(plugins:\s*\[)`)
-	updatedContent := regex.ReplaceAllString(content, `$1`+pluginConfig)
-	err := os.WriteFile(filePath, []byte(updatedContent), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to update babel.config.js: %v", err)
-	}
-
-	fmt.Printf("Successfully added react-native-dotenv plugin to %s\n", filePath)
-	return nil
-}
-
-// modifyBabelJSONConfig modifies a .babelrc file (JSON) to include the react-native-dotenv plugin
-func modifyBabelJSONConfig(filePath string) error {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read %s: %v", filePath, err)
-	}
-
-	// Parse the JSON content
-	var babelConfig map[string]interface{}
-	if err := json.Unmarshal(content, &babelConfig); err != nil {
-		return fmt.Errorf("failed to parse JSON in %s: %v", filePath, err)
-	}
-
-	// Check if the plugins array exists
-	plugins, ok := babelConfig["plugins"].([]interface{})
-	if !ok {
-		// If not, create the plugins array
-		plugins = []interface{}{}
-	}
-
-	// Check if react-native-dotenv is already in the plugins
-	for _, plugin := range plugins {
-		if pluginStr, isString := plugin.(string); isString && strings.Contains(pluginStr, "react-native-dotenv") {
-			fmt.Printf("react-native-dotenv plugin already present in %s\n", filePath)
-			return nil
-		}
-	}
-
-	// Add react-native-dotenv to the plugins
-	dotenvPlugin := map[string]interface{}{
-		"module":         "react-native-dotenv",
-		"moduleName":     "@env",
-		"blocklist":      nil,
-		"allowlist":      nil,
-		"safe":           false,
-		"allowUndefined": false,
-		"verbose":        false,
-	}
-
-	plugins = append(plugins, dotenvPlugin)
-	babelConfig["plugins"] = plugins
-
-	// Write the updated JSON back to the file
-	updatedContent, err := json.MarshalIndent(babelConfig, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to serialize updated JSON for %s: %v", filePath, err)
-	}
-
-	err = os.WriteFile(filePath, updatedContent, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write updated JSON to %s: %v", filePath, err)
-	}
-
-	fmt.Printf("Successfully added react-native-dotenv plugin to %s\n", filePath)
-	return nil
-}
-
 // DeleteFile removes the specified file from the project
 func DeleteFile(filePath string) error {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
@@ -448,33 +383,3 @@ func DeleteFile(filePath string) error {
 	fmt.Printf("Deleted file: %s\n", filePath)
 	return nil
 }
-
-// DestroyEnvFiles deletes the .env and env.d.ts (if applicable) files
-func DestroyEnvFiles(projectPath, projectType string) error {
-	// Delete the .env file
-	envFilePath := filepath.Join(projectPath, ".env")
-	err := DeleteFile(envFilePath)
-	if err != nil {
-		return err
-	}
-
-	// If Bare React Native, also delete env.d.ts
-	if projectType == "bare" {
-		envDTSPath := filepath.Join(projectPath, "env.d.ts")
-		err = DeleteFile(envDTSPath)
-		if err != nil {
-			return err
-		}
-	}
-
-	// Update the config to mark EnvInitialized as false
-	err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
-		cfg.EnvInitialized = false
-	})
-	if err != nil {
-		return fmt.Errorf("failed to update project config: %v", err)
-	}
-
-	fmt.Println("Environment configuration destroyed successfully.")
-	return nil
-}
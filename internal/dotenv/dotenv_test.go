@@ -0,0 +1,63 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestUpdateEnvFileKeepsConcurrentUpdates(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".env")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- UpdateEnvFile(path, func(envFile *DotenvFile) error {
+				envFile.AddOrUpdateKey(fmt.Sprintf("KEY_%d", i), "value")
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	envFile, err := ReadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := envFile.ListKeys(); len(keys) != 20 {
+		t.Errorf("%s holds %d keys, want 20: %v", path, len(keys), keys)
+	}
+}
+
+func TestUpdateEnvFileFailure(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("KEY=value\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("failure")
+	err := UpdateEnvFile(path, func(envFile *DotenvFile) error {
+		envFile.RemoveKey("KEY")
+		return failure
+	})
+	if err != failure {
+		t.Errorf("UpdateEnvFile() error = %v, want %v", err, failure)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "KEY=value\n" {
+		t.Errorf("%s was written after a failed update: %q", path, data)
+	}
+}
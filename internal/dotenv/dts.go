@@ -0,0 +1,263 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvDTSFileName is the TypeScript declaration file generated for bare projects
+const EnvDTSFileName = "env.d.ts"
+
+// EnvModuleName is the default module react-native-dotenv exposes the variables under
+const EnvModuleName = "@env"
+
+// ModuleName returns the module react-native-dotenv exposes the variables of
+// the project under, as configured with 'env configure'
+func ModuleName(projectPath string) string {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil || cfg.DotenvPlugin == nil || cfg.DotenvPlugin.ModuleName == "" {
+		return EnvModuleName
+	}
+	return cfg.DotenvPlugin.ModuleName
+}
+
+// ExpoEnvModulePath is the typed accessor module generated for Expo projects
+var ExpoEnvModulePath = filepath.Join("src", "config", "env.ts")
+
+// generatedHeader marks files written by the CLI so nobody edits them by hand
+const generatedHeader = `// This file is generated by mirorim-cli. Do not edit it by hand.
+// Run "mirorim-cli env types" to regenerate it.
+`
+
+// SyncTypes regenerates the type declarations of the project from its env files
+func SyncTypes(projectPath, projectType string) error {
+	switch projectType {
+	case "bare":
+		if err := SyncEnvDTS(projectPath); err != nil {
+			return err
+		}
+		return SyncAllowlist(projectPath)
+	case "expo":
+		return SyncExpoEnvModule(projectPath)
+	}
+	return nil
+}
+
+// TypesFileName returns the generated type file of a project type, relative to the project
+func TypesFileName(projectType string) string {
+	if projectType == "expo" {
+		return ExpoEnvModulePath
+	}
+	return EnvDTSFileName
+}
+
+// SyncEnvDTS regenerates env.d.ts from the keys of .env and of every
+// environment file, together with the schema when the project has one
+func SyncEnvDTS(projectPath string) error {
+	keys, schema, err := loadTypeSources(projectPath)
+	if err != nil {
+		return err
+	}
+	return GenerateEnvDTS(projectPath, ModuleName(projectPath), keys, schema)
+}
+
+// SyncExpoEnvModule regenerates src/config/env.ts from the keys of .env and
+// of every environment file, together with the schema when the project has one
+func SyncExpoEnvModule(projectPath string) error {
+	keys, schema, err := loadTypeSources(projectPath)
+	if err != nil {
+		return err
+	}
+	return GenerateExpoEnvModule(projectPath, keys, schema)
+}
+
+// loadTypeSources returns the project keys and the schema, which is nil
+// when the project has none
+func loadTypeSources(projectPath string) ([]string, *Schema, error) {
+	keys, err := ProjectKeys(projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schema, err := loadProjectSchema(projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, schema, nil
+}
+
+// ProjectKeys returns the sorted union of the keys of .env and of every
+// .env.<name> environment file in the project
+func ProjectKeys(projectPath string) ([]string, error) {
+	paths := []string{EnvFilePath(projectPath, "")}
+	entries, err := os.ReadDir(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory: %v", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isEnvironmentFile(entry.Name()) {
+			paths = append(paths, filepath.Join(projectPath, entry.Name()))
+		}
+	}
+
+	unique := make(map[string]bool)
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		envFile, err := ReadEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range envFile.ListKeys() {
+			unique[key] = true
+		}
+	}
+
+	return sortedKeys(unique), nil
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GenerateEnvDTS writes env.d.ts from scratch, declaring the given keys and
+// the keys of the schema in moduleName, using the schema types and descriptions
+// where available. Keys the schema marks as build-time-only or server-only are
+// left out, so importing them fails to type check. Keys are written in sorted
+// order so the output is deterministic.
+func GenerateEnvDTS(projectPath, moduleName string, keys []string, schema *Schema) error {
+	unique := make(map[string]bool)
+	for _, key := range keys {
+		unique[key] = true
+	}
+	if schema != nil {
+		for key := range schema.Variables {
+			unique[key] = true
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(generatedHeader)
+	content.WriteString("\ndeclare module " + strconv.Quote(moduleName) + " {\n")
+	for _, key := range sortedKeys(unique) {
+		// Keys that are not identifiers cannot be imported from the module
+		if !identifierPattern.MatchString(key) {
+			continue
+		}
+		if _, secret := schema.SecretExposure(key); secret {
+			continue
+		}
+
+		tsType := "string"
+		if schema != nil {
+			if variable, ok := schema.Variables[key]; ok {
+				content.WriteString(variable.JSDoc("  "))
+				tsType = variable.TypeScriptType()
+			}
+		}
+		content.WriteString(fmt.Sprintf("  export const %s: %s;\n", key, tsType))
+	}
+	content.WriteString("}\n")
+
+	err := fsutil.WriteFile(filepath.Join(projectPath, EnvDTSFileName), []byte(content.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", EnvDTSFileName, err)
+	}
+	return nil
+}
+
+// GenerateExpoEnvModule writes the typed accessor module of an Expo project.
+// Only EXPO_PUBLIC_ keys are inlined by Expo, so only those are declared: the
+// module augments NodeJS.ProcessEnv so process.env.EXPO_PUBLIC_* is typed
+// everywhere, and exports one constant per key without the prefix.
+func GenerateExpoEnvModule(projectPath string, keys []string, schema *Schema) error {
+	unique := make(map[string]bool)
+	for _, key := range keys {
+		unique[key] = true
+	}
+	if schema != nil {
+		for key := range schema.Variables {
+			unique[key] = true
+		}
+	}
+
+	var publicKeys []string
+	for _, key := range sortedKeys(unique) {
+		if IsPublicKey(key, "expo") {
+			publicKeys = append(publicKeys, key)
+		}
+	}
+
+	var declarations, accessors strings.Builder
+	for _, key := range publicKeys {
+		tsType := "string"
+		var doc string
+		if schema != nil {
+			if variable, ok := schema.Variables[key]; ok {
+				tsType = variable.TypeScriptType()
+				doc = variable.JSDoc("")
+			}
+		}
+
+		declarations.WriteString(indentLines(doc, "      "))
+		property := key
+		if !identifierPattern.MatchString(key) {
+			property = strconv.Quote(key)
+		}
+		declarations.WriteString(fmt.Sprintf("      %s: %s;\n", property, tsType))
+
+		// Expo only inlines static process.env.EXPO_PUBLIC_* member accesses
+		name := strings.TrimPrefix(key, ExpoPublicPrefix)
+		if identifierPattern.MatchString(key) && identifierPattern.MatchString(name) {
+			accessors.WriteString(doc)
+			accessors.WriteString(fmt.Sprintf("export const %s = process.env.%s;\n", name, key))
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(generatedHeader)
+	content.WriteString("\ndeclare global {\n  namespace NodeJS {\n    interface ProcessEnv {\n")
+	content.WriteString(declarations.String())
+	content.WriteString("    }\n  }\n}\n")
+	if accessors.Len() > 0 {
+		content.WriteString("\n" + accessors.String())
+	} else {
+		content.WriteString("\nexport {};\n")
+	}
+
+	modulePath := filepath.Join(projectPath, ExpoEnvModulePath)
+	if err := os.MkdirAll(filepath.Dir(modulePath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(ExpoEnvModulePath), err)
+	}
+	err := fsutil.WriteFile(modulePath, []byte(content.String()), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", ExpoEnvModulePath, err)
+	}
+	return nil
+}
+
+// identifierPattern matches names that are valid JavaScript identifiers
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// indentLines prefixes every line of text with indent
+func indentLines(text, indent string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	return indent + strings.Join(lines, indent) + "\n"
+}
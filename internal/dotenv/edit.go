@@ -0,0 +1,98 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+)
+
+// ReplaceEnvFile replaces the content of an env file in one step, after
+// checking that the new content parses, so readers never see a partially
+// written or invalid file. The mode of the existing file is kept.
+func ReplaceEnvFile(path, content string) error {
+	if _, err := Parse(content); err != nil {
+		return err
+	}
+
+	if err := snapshotBeforeWrite(path, content, "edit"); err != nil {
+		return err
+	}
+
+	err := fsutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// ValidateEdit parses the edited content of an env file and checks it against
+// the schema, when there is one. It returns the parsed file, or the problems
+// found: the parse error, or the variables that do not match the schema.
+func ValidateEdit(content string, schema *Schema) (*DotenvFile, []error) {
+	envFile, err := Parse(content)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if schema == nil {
+		return envFile, nil
+	}
+
+	var problems []error
+	for _, validationError := range schema.Validate(envFile) {
+		problems = append(problems, validationError)
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return envFile, nil
+}
+
+// EditUntilValid opens content in the editor through a private temporary file
+// until it passes ValidateEdit. The problems are printed and confirm asks
+// whether to re-open the editor. It returns the edited content and its parsed
+// form, or a nil file when nothing changed or the edit was abandoned.
+func EditUntilValid(name, content string, schema *Schema, edit func(path string) error, confirm func(message string) (bool, error)) (string, *DotenvFile, error) {
+	tmp, err := os.CreateTemp("", "mirorim-cli-*"+name)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(content)
+	tmp.Close()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to write temporary file: %v", err)
+	}
+
+	for {
+		if err := edit(tmp.Name()); err != nil {
+			return "", nil, err
+		}
+		data, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to read temporary file: %v", err)
+		}
+		edited := string(data)
+		if edited == content {
+			fmt.Println("No changes.")
+			return "", nil, nil
+		}
+
+		envFile, problems := ValidateEdit(edited, schema)
+		if len(problems) == 0 {
+			return edited, envFile, nil
+		}
+		for _, problem := range problems {
+			fmt.Printf("%s: %v\n", name, problem)
+		}
+
+		reopen, err := confirm("Re-open the editor to fix it?")
+		if err != nil {
+			return "", nil, err
+		}
+		if !reopen {
+			fmt.Println("Changes discarded.")
+			return "", nil, nil
+		}
+	}
+}
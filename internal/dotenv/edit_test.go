@@ -0,0 +1,124 @@
+package dotenv
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// editSchema requires a URL for the edit tests
+var editSchema = &Schema{Variables: map[string]*VariableSchema{
+	"API_URL": {Type: TypeURL, Required: true},
+}}
+
+func TestValidateEdit(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		schema   *Schema
+		problems []string
+	}{
+		{"valid", "API_URL=https://api.example.com\n", editSchema, nil},
+		{"without schema", "API_URL=not a url\n", nil, nil},
+		{"parse error", "API_URL=https://api.example.com\nKEY value\n", editSchema, []string{"line 2"}},
+		{"invalid value", "API_URL=not a url\n", editSchema, []string{"API_URL"}},
+		{"missing required", "DEBUG=true\n", editSchema, []string{"API_URL: required variable is missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile, problems := ValidateEdit(tt.content, tt.schema)
+			if len(problems) != len(tt.problems) {
+				t.Fatalf("ValidateEdit() problems = %v, want %d", problems, len(tt.problems))
+			}
+			for i, want := range tt.problems {
+				if !strings.Contains(problems[i].Error(), want) {
+					t.Errorf("problem %d = %v, want it to mention %q", i, problems[i], want)
+				}
+			}
+			if (envFile == nil) != (len(tt.problems) > 0) {
+				t.Errorf("ValidateEdit() file = %v with problems %v", envFile, problems)
+			}
+		})
+	}
+}
+
+func TestEditUntilValid(t *testing.T) {
+	const original = "API_URL=https://old.example.com\n"
+	errEditor := errors.New("editor failed")
+
+	tests := []struct {
+		name    string
+		edits   []string // content written by each run of the editor
+		answers []bool   // answers to the re-open prompt
+		editErr error
+		want    string
+		wantErr error
+		prompts int
+	}{
+		{name: "valid edit", edits: []string{"API_URL=https://new.example.com\n"}, want: "API_URL=https://new.example.com\n"},
+		{name: "no changes", edits: []string{original}},
+		{name: "fixed after re-opening", edits: []string{"API_URL=oops\n", "KEY value\n", "API_URL=https://new.example.com\n"}, answers: []bool{true, true}, want: "API_URL=https://new.example.com\n", prompts: 2},
+		{name: "abandoned", edits: []string{"API_URL=oops\n"}, answers: []bool{false}, prompts: 1},
+		{name: "editor error", editErr: errEditor, wantErr: errEditor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tmpPath string
+			runs, prompts := 0, 0
+			edit := func(path string) error {
+				tmpPath = path
+				if tt.editErr != nil {
+					return tt.editErr
+				}
+				// Each run starts from the content left by the previous one
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if runs == 0 && string(data) != original {
+					t.Errorf("editor opened with %q, want %q", data, original)
+				}
+				err = os.WriteFile(path, []byte(tt.edits[runs]), 0600)
+				runs++
+				return err
+			}
+			confirm := func(message string) (bool, error) {
+				answer := tt.answers[prompts]
+				prompts++
+				return answer, nil
+			}
+
+			got, envFile, err := EditUntilValid(".env", original, editSchema, edit, confirm)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EditUntilValid() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want || (envFile != nil) != (tt.want != "") {
+				t.Errorf("EditUntilValid() = %q, %v, want %q", got, envFile, tt.want)
+			}
+			if envFile != nil && envFile.Variables["API_URL"] != "https://new.example.com" {
+				t.Errorf("parsed API_URL = %q", envFile.Variables["API_URL"])
+			}
+			if prompts != tt.prompts {
+				t.Errorf("prompted %d times, want %d", prompts, tt.prompts)
+			}
+			if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+				t.Errorf("temporary file %s was not removed", tmpPath)
+			}
+		})
+	}
+}
+
+func TestEditUntilValidPromptError(t *testing.T) {
+	errPrompt := errors.New("prompt closed")
+	edit := func(path string) error {
+		return os.WriteFile(path, []byte("KEY value\n"), 0600)
+	}
+	confirm := func(message string) (bool, error) {
+		return false, errPrompt
+	}
+
+	if _, _, err := EditUntilValid(".env", "A=1\n", nil, edit, confirm); !errors.Is(err, errPrompt) {
+		t.Errorf("EditUntilValid() error = %v, want %v", err, errPrompt)
+	}
+}
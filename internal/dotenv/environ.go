@@ -0,0 +1,40 @@
+package dotenv
+
+import (
+	"sort"
+	"strings"
+)
+
+// MergeEnviron applies vars over an environment in the KEY=VALUE form of
+// os.Environ. Existing entries are replaced in place and new variables are
+// appended in sorted order.
+func MergeEnviron(environ []string, vars map[string]string) []string {
+	merged := make([]string, 0, len(environ)+len(vars))
+	seen := make(map[string]bool, len(vars))
+	for _, entry := range environ {
+		key := entry
+		if idx := strings.Index(entry, "="); idx > 0 {
+			key = entry[:idx]
+		}
+		if value, ok := vars[key]; ok {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			entry = key + "=" + value
+		}
+		merged = append(merged, entry)
+	}
+
+	var added []string
+	for key := range vars {
+		if !seen[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		merged = append(merged, key+"="+vars[key])
+	}
+	return merged
+}
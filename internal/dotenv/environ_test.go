@@ -0,0 +1,59 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeEnviron(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		vars    map[string]string
+		want    []string
+	}{
+		{
+			name:    "file values win in place",
+			environ: []string{"PATH=/bin", "API_URL=http://process", "HOME=/home/me"},
+			vars:    map[string]string{"API_URL": "http://file"},
+			want:    []string{"PATH=/bin", "API_URL=http://file", "HOME=/home/me"},
+		},
+		{
+			name:    "new variables appended in sorted order",
+			environ: []string{"PATH=/bin"},
+			vars:    map[string]string{"ZED": "z", "ALPHA": "a"},
+			want:    []string{"PATH=/bin", "ALPHA=a", "ZED=z"},
+		},
+		{
+			name:    "duplicate entries collapsed",
+			environ: []string{"API_URL=first", "PATH=/bin", "API_URL=second"},
+			vars:    map[string]string{"API_URL": "file"},
+			want:    []string{"API_URL=file", "PATH=/bin"},
+		},
+		{
+			name:    "unrelated entries kept as is",
+			environ: []string{"=C:=C:\\", "BROKEN", "EMPTY="},
+			vars:    map[string]string{"EMPTY": "set"},
+			want:    []string{"=C:=C:\\", "BROKEN", "EMPTY=set"},
+		},
+		{
+			name:    "empty values override",
+			environ: []string{"DEBUG=true"},
+			vars:    map[string]string{"DEBUG": ""},
+			want:    []string{"DEBUG="},
+		},
+		{
+			name:    "no variables",
+			environ: []string{"PATH=/bin"},
+			want:    []string{"PATH=/bin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MergeEnviron(tt.environ, tt.vars)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeEnviron() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
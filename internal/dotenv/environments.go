@@ -0,0 +1,240 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvironments are the environments listed even when their file does not exist yet
+var DefaultEnvironments = []string{"development", "staging", "production"}
+
+// EnvironmentStatus describes one environment file of the project
+type EnvironmentStatus struct {
+	Name        string
+	Path        string
+	Exists      bool
+	Active      bool
+	Keys        []string
+	MissingKeys []string
+}
+
+// EnvFileName returns the file name for an environment: ".env" for the
+// default environment and ".env.<name>" for named ones
+func EnvFileName(env string) string {
+	if env == "" {
+		return ".env"
+	}
+	return ".env." + env
+}
+
+// EnvFilePath returns the path of an environment file inside the project
+func EnvFilePath(projectPath, env string) string {
+	return filepath.Join(projectPath, EnvFileName(env))
+}
+
+// isEnvironmentFile reports whether a file name like ".env.staging" names an
+// environment, as opposed to the example, encrypted or backup files
+func isEnvironmentFile(name string) bool {
+	if !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	for _, suffix := range []string{".example", ".enc", ".bak", ".tmp", ".lock"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListEnvironments returns the status of the default environments and of every
+// other .env.<name> file found in the project, sorted by name. Missing keys are
+// computed against the union of keys of all existing environment files.
+func ListEnvironments(projectPath string) ([]EnvironmentStatus, error) {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, env := range DefaultEnvironments {
+		names[env] = true
+	}
+
+	entries, err := os.ReadDir(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory: %v", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && isEnvironmentFile(entry.Name()) {
+			names[strings.TrimPrefix(entry.Name(), ".env.")] = true
+		}
+	}
+
+	var statuses []EnvironmentStatus
+	allKeys := make(map[string]bool)
+	for name := range names {
+		status := EnvironmentStatus{
+			Name:   name,
+			Path:   EnvFilePath(projectPath, name),
+			Active: name == cfg.ActiveEnv,
+		}
+
+		if _, err := os.Stat(status.Path); err == nil {
+			envFile, err := LoadEnvFile(status.Path)
+			if err != nil {
+				return nil, err
+			}
+			status.Exists = true
+			status.Keys = envFile.ListKeys()
+			for _, key := range status.Keys {
+				allKeys[key] = true
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Report every key defined somewhere else but not in an existing file
+	for i := range statuses {
+		if !statuses[i].Exists {
+			continue
+		}
+		present := make(map[string]bool, len(statuses[i].Keys))
+		for _, key := range statuses[i].Keys {
+			present[key] = true
+		}
+		for key := range allKeys {
+			if !present[key] {
+				statuses[i].MissingKeys = append(statuses[i].MissingKeys, key)
+			}
+		}
+		sort.Strings(statuses[i].MissingKeys)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
+
+// UseEnvironment records env as the active environment and materializes its
+// file as .env, which is what both react-native-dotenv and Expo read. Unless
+// force is set, a hand-written .env is never overwritten.
+func UseEnvironment(projectPath, env string, force bool) error {
+	sourcePath := EnvFilePath(projectPath, env)
+	if _, err := os.Stat(sourcePath); os.IsNotExist(err) {
+		return fmt.Errorf("environment file %s does not exist", EnvFileName(env))
+	}
+
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return err
+	}
+
+	// A .env that was not generated by a previous "env use" holds the user's own values
+	if cfg.ActiveEnv == "" && !force {
+		envFile, err := LoadEnvFile(EnvFilePath(projectPath, ""))
+		if err != nil {
+			return err
+		}
+		if len(envFile.ListKeys()) > 0 {
+			return fmt.Errorf(".env contains variables that are not managed by an environment; move them to %s or use --force to overwrite", EnvFileName(env))
+		}
+	}
+
+	err = MaterializeEnvironment(projectPath, env)
+	if err != nil {
+		return err
+	}
+
+	err = config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+		cfg.ActiveEnv = env
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record active environment: %v", err)
+	}
+	return nil
+}
+
+// materializedHeaderPrefix starts the .env files written by MaterializeEnvironment
+const materializedHeaderPrefix = "# Generated by mirorim-cli from "
+
+// MaterializeEnvironment copies the variables of .env.<env> into .env,
+// preceded by a header that marks the file as generated
+func MaterializeEnvironment(projectPath, env string) error {
+	source, err := LoadEnvFile(EnvFilePath(projectPath, env))
+	if err != nil {
+		return err
+	}
+
+	header := fmt.Sprintf("%s%s. Edit that file or run 'env use' instead.", materializedHeaderPrefix, EnvFileName(env))
+	target := &DotenvFile{
+		Path:      EnvFilePath(projectPath, ""),
+		Variables: make(map[string]string),
+		lines:     []*envLine{{raw: header}, {}},
+	}
+	target.lines = append(target.lines, source.lines...)
+	for key, value := range source.Variables {
+		target.Variables[key] = value
+	}
+
+	return target.SaveEnvFile()
+}
+
+// PlanDestroyEnvironment works out how to remove a single environment: its
+// file is deleted, and when it is the active environment the .env
+// materialized from it is deleted too and no environment is active anymore
+func PlanDestroyEnvironment(projectPath, env string) (*DestroyPlan, error) {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	envPath := EnvFilePath(projectPath, env)
+	if _, err := os.Stat(envPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("environment file %s does not exist", EnvFileName(env))
+	}
+
+	plan := &DestroyPlan{}
+	plan.Steps = append(plan.Steps, DestroyStep{
+		Description: fmt.Sprintf("Delete %s (a snapshot is kept, see 'env history')", EnvFileName(env)),
+		apply: func() error {
+			if _, err := CreateSnapshot(projectPath, "remove "+env, []string{EnvFileName(env)}); err != nil {
+				return err
+			}
+			return DeleteFile(envPath)
+		},
+	})
+	if cfg.ActiveEnv != env {
+		return plan, nil
+	}
+
+	// The materialized .env is a copy of the environment, a hand-written one is kept
+	materializedPath := EnvFilePath(projectPath, "")
+	data, err := os.ReadFile(materializedPath)
+	if err == nil && strings.HasPrefix(string(data), materializedHeaderPrefix) {
+		plan.Steps = append(plan.Steps, DestroyStep{
+			Description: fmt.Sprintf("Delete .env, materialized from %s", EnvFileName(env)),
+			apply:       func() error { return DeleteFile(materializedPath) },
+		})
+	}
+	plan.Steps = append(plan.Steps, DestroyStep{
+		Description: fmt.Sprintf("Clear %s as the active environment in %s", env, config.ConfigFileName),
+		apply: func() error {
+			err := config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+				if cfg.ActiveEnv == env {
+					cfg.ActiveEnv = ""
+				}
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update project config: %v", err)
+			}
+			return nil
+		},
+	})
+	plan.Notes = append(plan.Notes, "Run 'env use <name>' to activate another environment.")
+	return plan, nil
+}
@@ -0,0 +1,160 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/fsutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExampleFileName is the committed template listing every variable without its value
+const ExampleFileName = ".env.example"
+
+// sectionHeaderPattern matches comments that may title a section, like
+// "# API" or "# Firebase auth": a few words and nothing else
+var sectionHeaderPattern = regexp.MustCompile(`^#+\s*([A-Za-z][A-Za-z0-9]*(?:[ _-][A-Za-z0-9]+){0,3})\s*$`)
+
+// sectionHeaders returns the indexes of the comment lines titling a section.
+// A title is only taken as such when, written as a key, it starts the key of a
+// variable right below it, as "# Firebase" does for FIREBASE_API_KEY. Such a
+// title repeats what the keys already show, while any other comment may hold
+// a value, e.g. "# old password hunter2".
+func (e *DotenvFile) sectionHeaders() map[int]bool {
+	headers := make(map[int]bool)
+	for i, line := range e.lines {
+		if !line.isComment() {
+			continue
+		}
+		match := sectionHeaderPattern.FindStringSubmatch(strings.TrimSpace(line.raw))
+		if match == nil {
+			continue
+		}
+		name := strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(match[1]))
+		for _, next := range e.lines[i+1:] {
+			if !next.isVariable() {
+				break
+			}
+			key := strings.TrimPrefix(strings.ToUpper(next.key), "EXPO_PUBLIC_")
+			if key == name || strings.HasPrefix(key, name+"_") {
+				headers[i] = true
+				break
+			}
+		}
+	}
+	return headers
+}
+
+// SyncExample regenerates .env.example from the given env file. Blank lines,
+// section headers and the order of the source are kept while values are
+// blanked. Other comments, inline ones included, are left out since they may
+// hold values. With
+// placeholders, values are replaced by a <key> placeholder and a comment hinting
+// at the expected kind of value. Values already set in the example are kept.
+func SyncExample(projectPath string, source *DotenvFile, placeholders bool) error {
+	examplePath := filepath.Join(projectPath, ExampleFileName)
+	lock, err := fsutil.Lock(examplePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	existing := make(map[string]string)
+	if _, err := os.Stat(examplePath); err == nil {
+		example, err := ReadEnvFile(examplePath)
+		if err != nil {
+			return err
+		}
+		existing = example.Variables
+	}
+
+	example := &DotenvFile{
+		Path:      examplePath,
+		Variables: make(map[string]string),
+	}
+	headers := source.sectionHeaders()
+	for i, line := range source.lines {
+		if line.isBlank() || headers[i] {
+			// Dropped comments must not leave runs of blank lines behind
+			last := len(example.lines) - 1
+			if line.isBlank() && (last < 0 || example.lines[last].isBlank()) {
+				continue
+			}
+			example.lines = append(example.lines, &envLine{raw: line.raw})
+			continue
+		}
+		if !line.isVariable() {
+			continue
+		}
+		if _, seen := example.Variables[line.key]; seen {
+			continue
+		}
+
+		exampleLine := &envLine{
+			export:   line.export,
+			key:      line.key,
+			value:    existing[line.key],
+			modified: true,
+		}
+		if placeholders {
+			if exampleLine.value == "" {
+				exampleLine.value = "<" + strings.ToLower(line.key) + ">"
+			}
+			if hint := describeValue(line.value); hint != "" {
+				exampleLine.comment = " " + hint
+			}
+		}
+		example.lines = append(example.lines, exampleLine)
+		example.Variables[line.key] = exampleLine.value
+	}
+
+	err = example.save(lock)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", ExampleFileName, err)
+	}
+	return nil
+}
+
+// describeValue returns a short hint about the kind of value, without revealing it
+func describeValue(value string) string {
+	if isBoolean(value) {
+		return "true or false"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return "a number"
+	}
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" && u.Host != "" {
+		return "a URL"
+	}
+	return ""
+}
+
+// CheckExample compares an env file with .env.example. missing lists keys of
+// the example that the env file lacks, extra lists keys of the env file that
+// the example does not declare. Both are sorted.
+func CheckExample(projectPath string, envFile *DotenvFile) ([]string, []string, error) {
+	example, err := ReadEnvFile(filepath.Join(projectPath, ExampleFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var missing, extra []string
+	for key := range example.Variables {
+		if _, ok := envFile.Variables[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	for key := range envFile.Variables {
+		if _, ok := example.Variables[key]; !ok {
+			extra = append(extra, key)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra, nil
+}
@@ -0,0 +1,97 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncExampleLeavesOutComments(t *testing.T) {
+	projectPath := t.TempDir()
+	source, err := Parse(strings.Join([]string{
+		materializedHeaderPrefix + ".env.staging. Edit that file or run 'env use' instead.",
+		"",
+		"# API",
+		"API_URL=https://api.example.com # prod: https://internal.example.com",
+		"# API_KEY=sk_live_0123456789abcdef",
+		"# token: sk_live_0123456789abcdef",
+		"API_KEY=sk_live_abcdef0123456789 # old: sk_live_0123456789abcdef",
+		"",
+		"# Debug",
+		"DEBUG=true",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SyncExample(projectPath, source, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(projectPath, ExampleFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# API\nAPI_URL=\nAPI_KEY=\n\n# Debug\nDEBUG=\n"
+	if string(data) != want {
+		t.Errorf(".env.example = %q, want %q", data, want)
+	}
+}
+
+func TestSyncExampleDropsValueComments(t *testing.T) {
+	projectPath := t.TempDir()
+	source, err := Parse(strings.Join([]string{
+		"# db pass letmein123",
+		"DB_PASS=letmein123",
+		"# old password hunter2",
+		"",
+		"# Feature flags",
+		"DEBUG=true",
+		"# Firebase auth",
+		"EXPO_PUBLIC_FIREBASE_AUTH_DOMAIN=demo.firebaseapp.com",
+		"",
+	}, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SyncExample(projectPath, source, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(projectPath, ExampleFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "DB_PASS=\n\nDEBUG=\n# Firebase auth\nEXPO_PUBLIC_FIREBASE_AUTH_DOMAIN=\n"
+	if string(data) != want {
+		t.Errorf(".env.example = %q, want %q", data, want)
+	}
+}
+
+func TestSyncExamplePlaceholders(t *testing.T) {
+	projectPath := t.TempDir()
+	source, err := Parse("API_URL=https://api.example.com # secret note\nDEBUG=true\nNAME=x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(projectPath, ExampleFileName), []byte("NAME=demo\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SyncExample(projectPath, source, true); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(projectPath, ExampleFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "API_URL=<api_url> # a URL\nDEBUG=<debug> # true or false\nNAME=demo\n"
+	if string(data) != want {
+		t.Errorf(".env.example = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,211 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"strings"
+)
+
+// Exposures of a variable declared in the schema. Public variables may be
+// inlined into the app bundle; build-time-only and server-only ones must not.
+const (
+	ExposurePublic = "public"
+	ExposureBuild  = "build"
+	ExposureServer = "server"
+)
+
+// Exposures lists the supported exposures
+var Exposures = []string{ExposurePublic, ExposureBuild, ExposureServer}
+
+// IsSecretExposure reports whether an exposure keeps a variable out of the app bundle
+func IsSecretExposure(exposure string) bool {
+	return exposure == ExposureBuild || exposure == ExposureServer
+}
+
+// ExposureLabel describes an exposure in messages
+func ExposureLabel(exposure string) string {
+	switch exposure {
+	case ExposureBuild:
+		return "build-time-only"
+	case ExposureServer:
+		return "server-only"
+	}
+	return "public"
+}
+
+// IsSecret reports whether the variable must stay out of the app bundle
+func (v *VariableSchema) IsSecret() bool {
+	return IsSecretExposure(v.Exposure)
+}
+
+// SecretExposure returns the exposure of a key the schema marks as
+// build-time-only or server-only. Expo keys are also looked up without their
+// EXPO_PUBLIC_ prefix, as a secret key must not have it.
+func (s *Schema) SecretExposure(key string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	for _, candidate := range []string{key, strings.TrimPrefix(key, ExpoPublicPrefix)} {
+		if variable, ok := s.Variables[candidate]; ok && variable.IsSecret() {
+			return variable.Exposure, true
+		}
+	}
+	return "", false
+}
+
+// HasSecrets reports whether the schema marks a variable as build-time-only or server-only
+func (s *Schema) HasSecrets() bool {
+	if s == nil {
+		return false
+	}
+	for _, variable := range s.Variables {
+		if variable.IsSecret() {
+			return true
+		}
+	}
+	return false
+}
+
+// GeneratesAllowlist reports whether the allowlist of the react-native-dotenv
+// plugin is generated from the public keys: when enabled with
+// 'env configure --allowlist-public', and by default once the schema marks a
+// variable as build-time-only or server-only, unless the project sets its own
+// allowlist or blocklist
+func GeneratesAllowlist(plugin *config.DotenvPlugin, schema *Schema) bool {
+	if plugin == nil {
+		return schema.HasSecrets()
+	}
+	if plugin.GenerateAllowlist {
+		return true
+	}
+	return len(plugin.Allowlist) == 0 && len(plugin.Blocklist) == 0 && schema.HasSecrets()
+}
+
+// BundleProtected reports whether the react-native-dotenv options keep key
+// out of the app bundle, through the allowlist or the blocklist
+func BundleProtected(plugin *config.DotenvPlugin, schema *Schema, key string) bool {
+	if GeneratesAllowlist(plugin, schema) {
+		// Secret keys are left out of the generated allowlist
+		return true
+	}
+	if plugin == nil {
+		return false
+	}
+	if len(plugin.Allowlist) > 0 && !containsKey(plugin.Allowlist, key) {
+		return true
+	}
+	return containsKey(plugin.Blocklist, key)
+}
+
+// containsKey reports whether keys holds key
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicKeys returns the keys of the env files and of the schema that the
+// schema does not mark as build-time-only or server-only, in sorted order.
+// Keys that cannot be imported, not being identifiers, are left out.
+func PublicKeys(projectPath string) ([]string, error) {
+	keys, schema, err := loadTypeSources(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make(map[string]bool)
+	for _, key := range keys {
+		unique[key] = true
+	}
+	if schema != nil {
+		for key := range schema.Variables {
+			unique[key] = true
+		}
+	}
+
+	public := []string{}
+	for _, key := range sortedKeys(unique) {
+		if _, secret := schema.SecretExposure(key); !secret && identifierPattern.MatchString(key) {
+			public = append(public, key)
+		}
+	}
+	return public, nil
+}
+
+// SyncAllowlist regenerates the allowlist of the react-native-dotenv plugin
+// entry from the public keys, when it is generated (see GeneratesAllowlist)
+func SyncAllowlist(projectPath string) error {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return err
+	}
+	if !cfg.EnvInitialized {
+		return nil
+	}
+	schema, err := loadProjectSchema(projectPath)
+	if err != nil {
+		return err
+	}
+	if !GeneratesAllowlist(cfg.DotenvPlugin, schema) {
+		return nil
+	}
+
+	fileName, updated, err := ConfigureBabelPlugin(projectPath, cfg.DotenvPlugin)
+	if err != nil {
+		return err
+	}
+	if updated {
+		fmt.Printf("Updated the react-native-dotenv allowlist in %s.\n", fileName)
+	}
+	return nil
+}
+
+// lintExposure reports the keys the schema marks as build-time-only or
+// server-only that can end up in the app bundle: Expo keys with the
+// EXPO_PUBLIC_ prefix, bare keys react-native-dotenv does not filter out, and
+// reads from app sources. Build tooling like app.config.js may read them.
+func lintExposure(envFile *DotenvFile, envFileName string, refs []SourceReference, projectType string, schema *Schema, plugin *config.DotenvPlugin) []LintIssue {
+	var issues []LintIssue
+	for _, key := range envFile.ListKeys() {
+		exposure, secret := schema.SecretExposure(key)
+		if !secret {
+			continue
+		}
+
+		var message string
+		switch {
+		case projectType == "expo" && strings.HasPrefix(key, ExpoPublicPrefix):
+			message = fmt.Sprintf("%s is marked %s, but the %s prefix inlines it into the app bundle", key, ExposureLabel(exposure), ExpoPublicPrefix)
+		case projectType == "bare" && !BundleProtected(plugin, schema, key):
+			message = fmt.Sprintf("%s is marked %s, but react-native-dotenv can inline it into the app bundle; drop it from the react-native-dotenv allowlist or add it to the blocklist", key, ExposureLabel(exposure))
+		default:
+			continue
+		}
+		issues = append(issues, LintIssue{Kind: LintExposed, Key: key, File: envFileName, Line: envFile.KeyLine(key), Message: message})
+	}
+
+	for _, ref := range refs {
+		if isToolingFile(ref.File) {
+			continue
+		}
+		exposure, secret := schema.SecretExposure(ref.Key)
+		if !secret {
+			continue
+		}
+		if ref.ProcessEnv && (projectType != "expo" || !strings.HasPrefix(ref.Key, ExpoPublicPrefix)) {
+			// Not inlined by the bundler
+			continue
+		}
+		issues = append(issues, LintIssue{
+			Kind:    LintExposed,
+			Key:     ref.Key,
+			File:    ref.File,
+			Line:    ref.Line,
+			Message: fmt.Sprintf("%s is marked %s and must not be read by the app", ref.Key, ExposureLabel(exposure)),
+		})
+	}
+	return issues
+}
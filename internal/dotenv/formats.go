@@ -0,0 +1,309 @@
+package dotenv
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Supported formats for env export and env import
+const (
+	FormatDotenv = "dotenv"
+	FormatJSON   = "json"
+	FormatYAML   = "yaml"
+	FormatShell  = "sh"
+	FormatGitHub = "github"
+	FormatDocker = "docker"
+)
+
+// Formats lists the supported import and export formats
+var Formats = []string{FormatDotenv, FormatJSON, FormatYAML, FormatShell, FormatGitHub, FormatDocker}
+
+// Variable is a single key and value in file order
+type Variable struct {
+	Key   string
+	Value string
+}
+
+// checkFormat returns an error for unsupported formats
+func checkFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// FormatFromPath guesses the format of a file from its name, defaulting to dotenv
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".sh":
+		return FormatShell
+	default:
+		return FormatDotenv
+	}
+}
+
+// Export renders variables in the given format
+func Export(vars []Variable, format string) (string, error) {
+	if err := checkFormat(format); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	switch format {
+	case FormatJSON:
+		out.WriteString("{")
+		for i, v := range vars {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			key, _ := json.Marshal(v.Key)
+			value, _ := json.Marshal(v.Value)
+			fmt.Fprintf(&out, "\n  %s: %s", key, value)
+		}
+		if len(vars) > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("}\n")
+	case FormatYAML:
+		for _, v := range vars {
+			// JSON strings are valid YAML double-quoted scalars
+			value, _ := json.Marshal(v.Value)
+			fmt.Fprintf(&out, "%s: %s\n", v.Key, value)
+		}
+	case FormatShell:
+		for _, v := range vars {
+			fmt.Fprintf(&out, "export %s=%s\n", v.Key, shellQuote(v.Value))
+		}
+	case FormatGitHub:
+		for _, v := range vars {
+			if !strings.ContainsAny(v.Value, "\n\r") {
+				fmt.Fprintf(&out, "%s=%s\n", v.Key, v.Value)
+				continue
+			}
+			// Multiline values use the heredoc syntax of $GITHUB_ENV files
+			delimiter := "EOF"
+			for strings.Contains(v.Value, delimiter) {
+				delimiter += "_"
+			}
+			fmt.Fprintf(&out, "%s<<%s\n%s\n%s\n", v.Key, delimiter, v.Value, delimiter)
+		}
+	case FormatDocker:
+		for _, v := range vars {
+			if strings.ContainsAny(v.Value, "\n\r") {
+				return "", fmt.Errorf("%s: multiline values are not supported by docker env files", v.Key)
+			}
+			fmt.Fprintf(&out, "%s=%s\n", v.Key, v.Value)
+		}
+	default:
+		for _, v := range vars {
+			fmt.Fprintf(&out, "%s=%s\n", v.Key, FormatValue(v.Value))
+		}
+	}
+	return out.String(), nil
+}
+
+// shellQuote quotes a value for POSIX shells in a way Parse also reads back
+func shellQuote(value string) string {
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`")
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// Import parses variables from content in the given format, in file order
+func Import(content, format string) ([]Variable, error) {
+	if err := checkFormat(format); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON:
+		return importJSON(content)
+	case FormatYAML:
+		return importYAML(content)
+	case FormatGitHub:
+		return importGitHub(content)
+	case FormatDocker:
+		return importDocker(content)
+	default:
+		// Shell exports written by Export are valid dotenv syntax
+		envFile, err := Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		var vars []Variable
+		for _, key := range envFile.ListKeys() {
+			vars = append(vars, Variable{Key: key, Value: envFile.Variables[key]})
+		}
+		return vars, nil
+	}
+}
+
+// importJSON reads a flat JSON object, keeping the order of its keys
+func importJSON(content string) ([]Variable, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("invalid JSON: expected an object of variables")
+	}
+
+	var vars []Variable
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		key := token.(string)
+
+		var raw interface{}
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON value for %s: %v", key, err)
+		}
+		switch value := raw.(type) {
+		case string:
+			vars = append(vars, Variable{Key: key, Value: value})
+		case json.Number:
+			vars = append(vars, Variable{Key: key, Value: value.String()})
+		case bool:
+			vars = append(vars, Variable{Key: key, Value: strconv.FormatBool(value)})
+		case nil:
+			vars = append(vars, Variable{Key: key, Value: ""})
+		default:
+			return nil, fmt.Errorf("invalid JSON value for %s: nested objects and arrays are not supported", key)
+		}
+	}
+	return vars, nil
+}
+
+// importYAML reads a flat YAML mapping of scalars
+func importYAML(content string) ([]Variable, error) {
+	var vars []Variable
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %d: nested values are not supported", i+1)
+		}
+
+		idx := strings.Index(trimmed, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY: value", i+1)
+		}
+		key := strings.TrimSpace(trimmed[:idx])
+		value, err := yamlScalar(strings.TrimSpace(trimmed[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		vars = append(vars, Variable{Key: key, Value: value})
+	}
+	return vars, nil
+}
+
+// yamlScalar decodes a plain, single-quoted or double-quoted YAML scalar
+func yamlScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		var value string
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return "", fmt.Errorf("invalid double-quoted value %s", raw)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("unterminated single-quoted value %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	case raw == "|" || raw == ">" || strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{"):
+		return "", fmt.Errorf("block scalars and collections are not supported")
+	case raw == "~" || raw == "null":
+		return "", nil
+	}
+
+	// Plain scalars end at a comment
+	if idx := strings.Index(raw, " #"); idx >= 0 {
+		raw = strings.TrimSpace(raw[:idx])
+	}
+	return raw, nil
+}
+
+// importGitHub reads a $GITHUB_ENV file, including heredoc multiline values
+func importGitHub(content string) ([]Variable, error) {
+	var vars []Variable
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		heredoc := strings.Index(line, "<<")
+		if heredoc > 0 && (eq < 0 || heredoc < eq) {
+			key, delimiter := line[:heredoc], line[heredoc+2:]
+			start := lineNumber
+			var value []string
+			closed := false
+			for scanner.Scan() {
+				lineNumber++
+				text := strings.TrimSuffix(scanner.Text(), "\r")
+				if text == delimiter {
+					closed = true
+					break
+				}
+				value = append(value, text)
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: missing closing delimiter %q", start, delimiter)
+			}
+			vars = append(vars, Variable{Key: key, Value: strings.Join(value, "\n")})
+			continue
+		}
+
+		if eq <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNumber)
+		}
+		vars = append(vars, Variable{Key: line[:eq], Value: line[eq+1:]})
+	}
+	return vars, scanner.Err()
+}
+
+// importDocker reads a docker --env-file file, where values are taken verbatim
+func importDocker(content string) ([]Variable, error) {
+	var vars []Variable
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			// Docker passes such variables through from the host, there is no value to import
+			continue
+		}
+		if eq == 0 {
+			return nil, fmt.Errorf("line %d: missing key", i+1)
+		}
+		vars = append(vars, Variable{Key: line[:eq], Value: line[eq+1:]})
+	}
+	return vars, nil
+}
@@ -0,0 +1,109 @@
+package dotenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	vars := []Variable{
+		{"PLAIN", "value"},
+		{"EMPTY", ""},
+		{"SPACES", "  padded value  "},
+		{"QUOTES", `it's "quoted"`},
+		{"DOLLAR", "$HOME and ${PATH}"},
+		{"BACKSLASH", `C:\dir\n`},
+		{"HASH", "a # b"},
+		{"EQUALS", "a=b<<c"},
+		{"UNICODE", "héllo wörld"},
+		{"MULTILINE", "line 1\nline 2\n"},
+	}
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			want := vars
+			if format == FormatDocker {
+				// Docker env files cannot hold multiline values
+				want = vars[:len(vars)-1]
+			}
+
+			content, err := Export(want, format)
+			if err != nil {
+				t.Fatalf("Export() failed: %v", err)
+			}
+			got, err := Import(content, format)
+			if err != nil {
+				t.Fatalf("Import() failed: %v\n%s", err, content)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip through %s:\n%s\ngot  %q\nwant %q", format, content, got, want)
+			}
+		})
+	}
+}
+
+func TestExportDockerRejectsMultiline(t *testing.T) {
+	_, err := Export([]Variable{{"KEY", "a\nb"}}, FormatDocker)
+	if err == nil {
+		t.Error("Export() of a multiline value to docker succeeded")
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		want    []Variable
+	}{
+		{"json scalars", FormatJSON, `{"B": 1.5, "A": true, "C": null, "D": "x"}`,
+			[]Variable{{"B", "1.5"}, {"A", "true"}, {"C", ""}, {"D", "x"}}},
+		{"yaml scalars", FormatYAML, "---\n# comment\nA: plain # note\nB: 'it''s'\nC: ~\nD: \"a\\nb\"\n",
+			[]Variable{{"A", "plain"}, {"B", "it's"}, {"C", ""}, {"D", "a\nb"}}},
+		{"github heredoc", FormatGitHub, "A=1\nB<<END\nx\ny\nEND\n",
+			[]Variable{{"A", "1"}, {"B", "x\ny"}}},
+		{"docker", FormatDocker, "# comment\n  A=1 # kept\nPASSED_THROUGH\nB='q'\n",
+			[]Variable{{"A", "1 # kept"}, {"B", "'q'"}}},
+		{"shell", FormatShell, "export A='1'\nexport B=\"\\$x\"\n",
+			[]Variable{{"A", "1"}, {"B", "$x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Import(tt.content, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Import() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		message string
+	}{
+		{"unknown format", "toml", "", "unknown format"},
+		{"json array", FormatJSON, `["A"]`, "expected an object"},
+		{"json nested", FormatJSON, `{"A": {"B": 1}}`, "nested objects"},
+		{"yaml nested", FormatYAML, "A:\n  B: 1\n", "line 2: nested values"},
+		{"yaml block", FormatYAML, "A: |\n", "block scalars"},
+		{"github unclosed", FormatGitHub, "A<<EOF\nx\n", "missing closing delimiter"},
+		{"docker missing key", FormatDocker, "=x\n", "missing key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Import(tt.content, tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Import() error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreHeader introduces the patterns added to .gitignore by the CLI
+const gitignoreHeader = "# Local environment files (mirorim-cli)"
+
+// GitignorePatterns keep env files and their backups out of git, while the
+// example and the encrypted files stay committable
+var GitignorePatterns = []string{
+	".env",
+	".env.*",
+	"!" + ExampleFileName,
+	"!.env" + EncryptedSuffix,
+	"!.env.*" + EncryptedSuffix,
+	filepath.ToSlash(BackupDir) + "/",
+}
+
+// EnsureGitignore adds the env file patterns missing from the .gitignore of
+// the project, creating the file if needed, and returns the added patterns
+func EnsureGitignore(projectPath string) ([]string, error) {
+	gitignorePath := filepath.Join(projectPath, ".gitignore")
+	data, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read .gitignore: %v", err)
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, pattern := range GitignorePatterns {
+		if !present[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	if !present[gitignoreHeader] {
+		content += gitignoreHeader + "\n"
+	}
+	content += strings.Join(missing, "\n") + "\n"
+
+	err = fsutil.WriteFile(gitignorePath, []byte(content), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write .gitignore: %v", err)
+	}
+	return missing, nil
+}
+
+// IsSecretFileName reports whether a file name is a plain env file or backup
+// that must never be committed, as opposed to the example and encrypted files
+func IsSecretFileName(name string) bool {
+	if name != ".env" && !strings.HasPrefix(name, ".env.") {
+		return false
+	}
+	return name != ExampleFileName && !strings.HasSuffix(name, EncryptedSuffix)
+}
+
+// isKeyFile reports whether a file holds a project encryption key
+func isKeyFile(filePath string) bool {
+	if filepath.Ext(filePath) != ".key" {
+		return false
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return false
+	}
+	_, err = decodeKey(strings.TrimSpace(string(data)), filePath)
+	return err == nil
+}
+
+// IsGitRepository reports whether the project is inside a git work tree
+func IsGitRepository(projectPath string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = projectPath
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// TrackedSecretFiles returns the files of the git index that hold secrets:
+// env files, their backups and project key files. Paths are relative to
+// the project.
+func TrackedSecretFiles(projectPath string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "-z", "--cached")
+	cmd.Dir = projectPath
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var tracked []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file == "" {
+			continue
+		}
+		if IsSecretFileName(path.Base(file)) || isKeyFile(filepath.Join(projectPath, filepath.FromSlash(file))) {
+			tracked = append(tracked, file)
+		}
+	}
+	return tracked, nil
+}
+
+// IsGitIgnored reports whether git ignores the given project file
+func IsGitIgnored(projectPath, name string) bool {
+	cmd := exec.Command("git", "check-ignore", "-q", "--no-index", name)
+	cmd.Dir = projectPath
+	return cmd.Run() == nil
+}
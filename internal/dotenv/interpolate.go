@@ -0,0 +1,196 @@
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// literalDollar marks an escaped "\$" in a template so it is not expanded
+const literalDollar = "\x00"
+
+// resolver expands the references of one env file
+type resolver struct {
+	templates     map[string]string
+	literals      map[string]string
+	resolved      map[string]string
+	stack         []string
+	useProcessEnv bool
+}
+
+// Resolve returns the variables of the file with ${VAR}, ${VAR:-default},
+// ${VAR-default} and $VAR references expanded. References are looked up in
+// the file first and, with useProcessEnv, in the process environment. Unset
+// references expand to an empty string and reference cycles are errors.
+// Single-quoted and backtick-quoted values are taken literally.
+func (e *DotenvFile) Resolve(useProcessEnv bool) (map[string]string, error) {
+	r := &resolver{
+		templates:     make(map[string]string),
+		literals:      make(map[string]string),
+		resolved:      make(map[string]string),
+		useProcessEnv: useProcessEnv,
+	}
+
+	// The last occurrence of a key wins, like when loading the file
+	for _, line := range e.lines {
+		if !line.isVariable() {
+			continue
+		}
+		if line.expand {
+			r.templates[line.key] = line.template
+			delete(r.literals, line.key)
+		} else {
+			r.literals[line.key] = line.value
+			delete(r.templates, line.key)
+		}
+	}
+
+	result := make(map[string]string, len(e.Variables))
+	for _, key := range e.ListKeys() {
+		value, _, err := r.lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// lookup returns the expanded value of a name and whether it is set
+func (r *resolver) lookup(name string) (string, bool, error) {
+	if value, ok := r.literals[name]; ok {
+		return value, true, nil
+	}
+	if value, ok := r.resolved[name]; ok {
+		return value, true, nil
+	}
+
+	template, ok := r.templates[name]
+	if !ok {
+		if r.useProcessEnv {
+			value, ok := os.LookupEnv(name)
+			return value, ok, nil
+		}
+		return "", false, nil
+	}
+
+	for i, key := range r.stack {
+		if key == name {
+			cycle := append(append([]string{}, r.stack[i:]...), name)
+			return "", false, fmt.Errorf("reference cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	r.stack = append(r.stack, name)
+	value, err := r.expand(template)
+	r.stack = r.stack[:len(r.stack)-1]
+	if err != nil {
+		return "", false, fmt.Errorf("%s: %v", name, err)
+	}
+
+	value = strings.ReplaceAll(value, literalDollar, "$")
+	r.resolved[name] = value
+	return value, true, nil
+}
+
+// expand replaces the references in a template
+func (r *resolver) expand(template string) (string, error) {
+	var out strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i+1 >= len(template) {
+			out.WriteByte(template[i])
+			continue
+		}
+
+		// ${VAR}, ${VAR:-default} or ${VAR-default}
+		if template[i+1] == '{' {
+			end := matchingBrace(template, i+1)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated reference %q", template[i:])
+			}
+			value, err := r.expandBraced(template[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(value)
+			i = end
+			continue
+		}
+
+		// $VAR
+		end := i + 1
+		for end < len(template) && isNameChar(template[end], end == i+1) {
+			end++
+		}
+		if end == i+1 {
+			out.WriteByte('$')
+			continue
+		}
+		value, _, err := r.lookup(template[i+1 : end])
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(value)
+		i = end - 1
+	}
+	return out.String(), nil
+}
+
+// expandBraced expands the content of a ${...} reference
+func (r *resolver) expandBraced(body string) (string, error) {
+	name, fallback, hasDefault := body, "", false
+	onlyUnset := false
+	if idx := strings.Index(body, ":-"); idx >= 0 {
+		name, fallback, hasDefault = body[:idx], body[idx+2:], true
+	} else if idx := strings.Index(body, "-"); idx >= 0 {
+		name, fallback, hasDefault, onlyUnset = body[:idx], body[idx+1:], true, true
+	}
+
+	if name == "" || !isName(name) {
+		return "", fmt.Errorf("invalid reference ${%s}", body)
+	}
+
+	value, set, err := r.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	if hasDefault && (!set || (!onlyUnset && value == "")) {
+		return r.expand(fallback)
+	}
+	return value, nil
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or -1
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isName reports whether s is a valid reference name
+func isName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isNameChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isNameChar reports whether c may appear in a reference name
+func isNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
@@ -0,0 +1,96 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+	}{
+		{"braced", "HOST=api.io\nURL=https://${HOST}/v1", "URL", "https://api.io/v1"},
+		{"bare", "HOST=api.io\nURL=https://$HOST/v1", "URL", "https://api.io/v1"},
+		{"forward reference", "URL=$HOST\nHOST=api.io", "URL", "api.io"},
+		{"chained", "A=a\nB=${A}b\nC=${B}c", "C", "abc"},
+		{"double quoted", "A=a\nB=\"${A} b\"", "B", "a b"},
+		{"single quoted", "A=a\nB='${A}'", "B", "${A}"},
+		{"backtick quoted", "A=a\nB=`$A`", "B", "$A"},
+		{"escaped dollar", "A=a\nB=\"\\${A} \\$A\"", "B", "${A} $A"},
+		{"escaped dollar unquoted", "A=a\nB=\\$A", "B", "$A"},
+		{"unset", "B=x${MISSING}y", "B", "xy"},
+		{"default when unset", "B=${MISSING:-fallback}", "B", "fallback"},
+		{"default when empty", "A=\nB=${A:-fallback}", "B", "fallback"},
+		{"dash default keeps empty", "A=\nB=${A-fallback}", "B", ""},
+		{"dash default when unset", "B=${MISSING-fallback}", "B", "fallback"},
+		{"nested default", "A=a\nB=${MISSING:-${A}!}", "B", "a!"},
+		{"lone dollar", "B=cost $ 5", "B", "cost $ 5"},
+		{"trailing dollar", "B=cost$", "B", "cost$"},
+		{"last definition wins", "A=1\nB=$A\nA=2", "B", "2"},
+		{"literal redefinition", "A=1\nA='$x'\nB=$A", "B", "$x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile, err := Parse(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resolved, err := envFile.Resolve(false)
+			if err != nil {
+				t.Fatalf("Resolve() failed: %v", err)
+			}
+			if got := resolved[tt.key]; got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveProcessEnv(t *testing.T) {
+	t.Setenv("MIRORIM_TEST_HOST", "from-env")
+	envFile, err := Parse("URL=${MIRORIM_TEST_HOST}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for useProcessEnv, want := range map[bool]string{false: "", true: "from-env"} {
+		resolved, err := envFile.Resolve(useProcessEnv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resolved["URL"] != want {
+			t.Errorf("Resolve(%v) URL = %q, want %q", useProcessEnv, resolved["URL"], want)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"self reference", "A=$A", "reference cycle: A -> A"},
+		{"cycle", "A=${B}\nB=${C}\nC=$A", "reference cycle: A -> B -> C -> A"},
+		{"unterminated", "A=${B", "unterminated reference"},
+		{"invalid name", "A=${1B}", "invalid reference"},
+		{"empty name", "A=${:-x}", "invalid reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile, err := Parse(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = envFile.Resolve(false)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Resolve() error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind is the kind of a JavaScript token
+type tokenKind int
+
+const (
+	punctToken tokenKind = iota
+	identToken
+	stringToken
+	numberToken
+	templateToken
+	regexToken
+)
+
+// token is a JavaScript or JSON5 token, located by its byte offsets in the
+// source. Comments and whitespace are skipped.
+type token struct {
+	kind  tokenKind
+	text  string
+	start int
+	end   int
+}
+
+// is reports whether the token is the given punctuator or identifier
+func (t token) is(text string) bool {
+	return (t.kind == punctToken || t.kind == identToken) && t.text == text
+}
+
+// isOpening reports whether the token opens a bracket
+func (t token) isOpening() bool {
+	return t.kind == punctToken && strings.Contains("([{", t.text)
+}
+
+// isClosing reports whether the token closes a bracket
+func (t token) isClosing() bool {
+	return t.kind == punctToken && strings.Contains(")]}", t.text)
+}
+
+// value returns the value of a string token
+func (t token) value() string {
+	var value strings.Builder
+	body := t.text[1 : len(t.text)-1]
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' || i+1 == len(body) {
+			value.WriteByte(body[i])
+			continue
+		}
+		i++
+		switch body[i] {
+		case 'n':
+			value.WriteByte('\n')
+		case 't':
+			value.WriteByte('\t')
+		case 'r':
+			value.WriteByte('\r')
+		default:
+			value.WriteByte(body[i])
+		}
+	}
+	return value.String()
+}
+
+// regexKeywords are the keywords after which a slash starts a regular expression
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true,
+	"of": true, "new": true, "delete": true, "void": true, "throw": true, "instanceof": true,
+}
+
+// tokenize splits JavaScript or JSON5 source into tokens. It knows enough of
+// the language to find brackets reliably: strings, template literals,
+// regular expressions and comments are read as a whole.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//") || (i == 0 && strings.HasPrefix(src, "#!")):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment on line %d", lineOf(src, i))
+			}
+			i += end + 4
+		case c == '\'' || c == '"':
+			end, err := quotedEnd(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: stringToken, text: src[i:end], start: i, end: end})
+			i = end
+		case c == '`':
+			end, err := templateEnd(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: templateToken, text: src[i:end], start: i, end: end})
+			i = end
+		case c == '/' && regexAllowed(tokens):
+			end, err := regexEnd(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: regexToken, text: src[i:end], start: i, end: end})
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(src) && (isIdentStart(src[end]) || (src[end] >= '0' && src[end] <= '9')) {
+				end++
+			}
+			tokens = append(tokens, token{kind: identToken, text: src[i:end], start: i, end: end})
+			i = end
+		case c >= '0' && c <= '9' || (c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9'):
+			end := i + 1
+			for end < len(src) && (isIdentStart(src[end]) || (src[end] >= '0' && src[end] <= '9') || src[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, token{kind: numberToken, text: src[i:end], start: i, end: end})
+			i = end
+		default:
+			length := 1
+			for _, punct := range []string{"...", "=>"} {
+				if strings.HasPrefix(src[i:], punct) {
+					length = len(punct)
+				}
+			}
+			tokens = append(tokens, token{kind: punctToken, text: src[i : i+length], start: i, end: i + length})
+			i += length
+		}
+	}
+	return tokens, nil
+}
+
+// isIdentStart reports whether c can start an identifier. Bytes of multi-byte
+// characters are accepted so identifiers with non-ASCII letters stay whole.
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// regexAllowed reports whether a slash following tokens starts a regular
+// expression rather than a division
+func regexAllowed(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	last := tokens[len(tokens)-1]
+	switch last.kind {
+	case punctToken:
+		return !strings.Contains(")]}", last.text)
+	case identToken:
+		return regexKeywords[last.text]
+	}
+	return false
+}
+
+// quotedEnd returns the offset after the string literal starting at start
+func quotedEnd(src string, start int) (int, error) {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, nil
+		case '\n':
+			return 0, fmt.Errorf("unterminated string on line %d", lineOf(src, start))
+		}
+	}
+	return 0, fmt.Errorf("unterminated string on line %d", lineOf(src, start))
+}
+
+// templateEnd returns the offset after the template literal starting at
+// start, skipping over the strings and templates of its substitutions
+func templateEnd(src string, start int) (int, error) {
+	depth := 0
+	for i := start + 1; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+		case depth == 0 && src[i] == '`':
+			return i + 1, nil
+		case depth == 0 && strings.HasPrefix(src[i:], "${"):
+			depth++
+			i++
+		case depth > 0 && src[i] == '{':
+			depth++
+		case depth > 0 && src[i] == '}':
+			depth--
+		case depth > 0 && (src[i] == '\'' || src[i] == '"'):
+			end, err := quotedEnd(src, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end - 1
+		case depth > 0 && src[i] == '`':
+			end, err := templateEnd(src, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end - 1
+		}
+	}
+	return 0, fmt.Errorf("unterminated template literal on line %d", lineOf(src, start))
+}
+
+// regexEnd returns the offset after the regular expression starting at
+// start, including its flags
+func regexEnd(src string, start int) (int, error) {
+	inClass := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return 0, fmt.Errorf("unterminated regular expression on line %d", lineOf(src, start))
+		case '/':
+			if inClass {
+				continue
+			}
+			end := i + 1
+			for end < len(src) && isIdentStart(src[end]) {
+				end++
+			}
+			return end, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated regular expression on line %d", lineOf(src, start))
+}
+
+// lineOf returns the 1-based line number of offset
+func lineOf(src string, offset int) int {
+	return strings.Count(src[:offset], "\n") + 1
+}
+
+// matchingToken returns the index of the token closing the bracket opened at open
+func matchingToken(tokens []token, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isOpening():
+			depth++
+		case tokens[i].isClosing():
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced %q", tokens[open].text)
+}
+
+// span is a range of tokens, end excluded
+type span struct {
+	start int
+	end   int
+}
+
+// listItems returns the comma separated items of the array, object or
+// argument list opened at token open
+func listItems(tokens []token, open int) ([]span, error) {
+	closing, err := matchingToken(tokens, open)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []span
+	start, depth := open+1, 0
+	for i := open + 1; i <= closing; i++ {
+		switch {
+		case i == closing || (depth == 0 && tokens[i].is(",")):
+			if i > start {
+				items = append(items, span{start, i})
+			}
+			start = i + 1
+		case tokens[i].isOpening():
+			depth++
+		case tokens[i].isClosing():
+			depth--
+		}
+	}
+	return items, nil
+}
+
+// objectProperty is a property of an object literal. Spread elements, methods
+// and computed keys have an empty key.
+type objectProperty struct {
+	span
+	key   string
+	value int // index of the first token of the value
+}
+
+// objectProperties returns the properties of the object literal opened at token open
+func objectProperties(tokens []token, open int) ([]objectProperty, error) {
+	items, err := listItems(tokens, open)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make([]objectProperty, 0, len(items))
+	for _, item := range items {
+		property := objectProperty{span: item, value: item.start}
+		first := tokens[item.start]
+		if item.end-item.start > 2 && tokens[item.start+1].is(":") {
+			switch first.kind {
+			case identToken, numberToken:
+				property.key = first.text
+			case stringToken:
+				property.key = first.value()
+			}
+			property.value = item.start + 2
+		}
+		properties = append(properties, property)
+	}
+	return properties, nil
+}
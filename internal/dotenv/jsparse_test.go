@@ -0,0 +1,102 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"punctuators", "a => ({ ...b })", []string{"a", "=>", "(", "{", "...", "b", "}", ")"}},
+		{"comments", "#!/usr/bin/env node\na // b\n/* c */ d", []string{"a", "d"}},
+		{"strings", `'it\'s' "a \"b\"" 'x/*y*/'`, []string{`'it\'s'`, `"a \"b\""`, `'x/*y*/'`}},
+		{"template", "`a ${ {b: '}'}[`c`] } d` e", []string{"`a ${ {b: '}'}[`c`] } d`", "e"}},
+		{"regex", "x = /[/]+\\//gi; return /a/", []string{"x", "=", "/[/]+\\//gi", ";", "return", "/a/"}},
+		{"division", "a / b / (c) / 2", []string{"a", "/", "b", "/", "(", "c", ")", "/", "2"}},
+		{"numbers", "1.5 .5 0x1F", []string{"1.5", ".5", "0x1F"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(tokens))
+			for i, tok := range tokens {
+				got[i] = tok.text
+				if tt.src[tok.start:tok.end] != tok.text {
+					t.Errorf("token %q is at %d:%d, which holds %q", tok.text, tok.start, tok.end, tt.src[tok.start:tok.end])
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") || len(got) != len(tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeKinds(t *testing.T) {
+	tokens, err := tokenize("a 'b' 1 `c`, /d/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []tokenKind{identToken, stringToken, numberToken, templateToken, punctToken, regexToken}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, kind := range want {
+		if tokens[i].kind != kind {
+			t.Errorf("token %q has kind %d, want %d", tokens[i].text, tokens[i].kind, kind)
+		}
+	}
+	if got := tokens[1].value(); got != "b" {
+		t.Errorf("value() = %q, want b", got)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"a\n'b", "unterminated string on line 2"},
+		{"a /* b", "unterminated comment on line 1"},
+		{"`a ${b}", "unterminated template literal on line 1"},
+		{"x = /a\n/", "unterminated regular expression on line 1"},
+	}
+
+	for _, tt := range tests {
+		if _, err := tokenize(tt.src); err == nil || err.Error() != tt.want {
+			t.Errorf("tokenize(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestObjectProperties(t *testing.T) {
+	src := "{ a: 1, 'b': [2, 3], ...c, d() {}, [e]: 4, }"
+	tokens, err := tokenize(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties, err := objectProperties(tokens, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "b", "", "", ""}
+	if len(properties) != len(want) {
+		t.Fatalf("got %d properties, want %d", len(properties), len(want))
+	}
+	for i, key := range want {
+		if properties[i].key != key {
+			t.Errorf("property %d has key %q, want %q", i, properties[i].key, key)
+		}
+	}
+	if got := tokens[properties[1].value].text; got != "[" {
+		t.Errorf("value of b starts with %q, want [", got)
+	}
+}
@@ -0,0 +1,48 @@
+package dotenv
+
+import "strings"
+
+// ExpoPublicPrefix is the prefix Expo requires for variables inlined into the app bundle
+const ExpoPublicPrefix = "EXPO_PUBLIC_"
+
+// IsPublicKey reports whether a key ends up in the JS bundle. In Expo projects
+// only EXPO_PUBLIC_ keys are inlined, bare projects expose every key through
+// react-native-dotenv.
+func IsPublicKey(key, projectType string) bool {
+	if projectType == "expo" {
+		return strings.HasPrefix(key, ExpoPublicPrefix)
+	}
+	return true
+}
+
+// ResolveKey returns the key as it is stored in the file. For Expo projects a
+// key given without its EXPO_PUBLIC_ prefix is also looked up with the prefix.
+func (e *DotenvFile) ResolveKey(key, projectType string) (string, bool) {
+	if _, ok := e.Variables[key]; ok {
+		return key, true
+	}
+	if projectType == "expo" {
+		prefixed := EnsureExpoPrefix(strings.ToUpper(key))
+		if _, ok := e.Variables[prefixed]; ok {
+			return prefixed, true
+		}
+	}
+	upper := strings.ToUpper(key)
+	if _, ok := e.Variables[upper]; ok {
+		return upper, true
+	}
+	return "", false
+}
+
+// MaskValue hides a secret value for display. Long values keep their first
+// and last two characters so they can still be told apart.
+func MaskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	runes := []rune(value)
+	if len(runes) < 12 {
+		return "********"
+	}
+	return string(runes[:2]) + "********" + string(runes[len(runes)-2:])
+}
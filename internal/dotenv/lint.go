@@ -0,0 +1,259 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/config"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of issues reported by Lint
+const (
+	LintUnused      = "unused"
+	LintUndefined   = "undefined"
+	LintWrongPrefix = "wrong-prefix"
+	LintExposed     = "exposed"
+)
+
+// SourceReference is a variable read by the project sources
+type SourceReference struct {
+	Key  string
+	File string
+	Line int
+	// ProcessEnv is set for process.env accesses, as opposed to @env imports
+	ProcessEnv bool
+}
+
+// LintIssue is a problem found by Lint, located in a source or env file
+type LintIssue struct {
+	Kind    string `json:"kind"`
+	Key     string `json:"key"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+var (
+	// import { A, B as C } from '@env' and const { A } = require('@env')
+	namedImportPattern = regexp.MustCompile(`(?s)import\s+(?:type\s+)?\{([^}]*)\}\s*from\s*['"]([^'"]+)['"]`)
+	requirePattern     = regexp.MustCompile(`(?s)(?:const|let|var)\s*\{([^}]*)\}\s*=\s*require\(\s*['"]([^'"]+)['"]\s*\)`)
+	// import * as env from '@env' and import env from '@env'
+	namespaceImportPattern = regexp.MustCompile(`import\s+(?:\*\s+as\s+)?([A-Za-z_$][\w$]*)\s+from\s*['"]([^'"]+)['"]`)
+	// process.env.KEY, process.env['KEY'] and const { KEY } = process.env
+	processEnvPattern         = regexp.MustCompile(`process\.env(?:\.([A-Za-z_][A-Za-z0-9_]*)|\[\s*['"]([^'"]+)['"]\s*\])`)
+	processEnvDestructPattern = regexp.MustCompile(`(?s)\{([^{}]*)\}\s*=\s*process\.env\b`)
+)
+
+// processEnvBuiltins are set by the bundlers themselves and never come from env files
+var processEnvBuiltins = map[string]bool{
+	"NODE_ENV":                true,
+	"EXPO_OS":                 true,
+	"EXPO_ROUTER_APP_ROOT":    true,
+	"EXPO_ROUTER_IMPORT_MODE": true,
+	"EXPO_PROJECT_ROOT":       true,
+}
+
+// ScanSources finds the variables read by the JavaScript and TypeScript
+// sources of a project, through imports from the react-native-dotenv module,
+// imports from the generated Expo accessor module and process.env accesses
+func ScanSources(projectPath, projectType string) ([]SourceReference, error) {
+	var refs []SourceReference
+	moduleName := ModuleName(projectPath)
+	err := walkSourceFiles(projectPath, func(rel, content string) error {
+		refs = append(refs, scanSource(rel, content, moduleName, projectType)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sources: %v", err)
+	}
+	return refs, nil
+}
+
+// scanSource finds the variables read by a single source file, where
+// moduleName is the react-native-dotenv module
+func scanSource(file, content, moduleName, projectType string) []SourceReference {
+	var refs []SourceReference
+	add := func(key string, offset int, processEnv bool) {
+		refs = append(refs, SourceReference{
+			Key:        key,
+			File:       file,
+			Line:       strings.Count(content[:offset], "\n") + 1,
+			ProcessEnv: processEnv,
+		})
+	}
+
+	// Named imports and destructured requires list the keys directly
+	for _, pattern := range []*regexp.Regexp{namedImportPattern, requirePattern} {
+		for _, m := range pattern.FindAllStringSubmatchIndex(content, -1) {
+			prefix, ok := envModulePrefix(content[m[4]:m[5]], moduleName, projectType)
+			if !ok {
+				continue
+			}
+			for _, name := range destructuredNames(content, m[2], m[3]) {
+				add(prefix+name.key, name.offset, false)
+			}
+		}
+	}
+
+	// Namespace and default imports are followed by member accesses
+	for _, m := range namespaceImportPattern.FindAllStringSubmatchIndex(content, -1) {
+		prefix, ok := envModulePrefix(content[m[4]:m[5]], moduleName, projectType)
+		if !ok {
+			continue
+		}
+		alias := regexp.MustCompile(`\b` + regexp.QuoteMeta(content[m[2]:m[3]]) + `\.([A-Za-z_][A-Za-z0-9_]*)`)
+		for _, access := range alias.FindAllStringSubmatchIndex(content, -1) {
+			if access[0] > 0 && content[access[0]-1] == '.' {
+				// A property of another object, like process.env.KEY
+				continue
+			}
+			add(prefix+content[access[2]:access[3]], access[2], false)
+		}
+	}
+
+	for _, m := range processEnvPattern.FindAllStringSubmatchIndex(content, -1) {
+		if m[2] >= 0 {
+			add(content[m[2]:m[3]], m[2], true)
+		} else {
+			add(content[m[4]:m[5]], m[4], true)
+		}
+	}
+	for _, m := range processEnvDestructPattern.FindAllStringSubmatchIndex(content, -1) {
+		for _, name := range destructuredNames(content, m[2], m[3]) {
+			add(name.key, name.offset, true)
+		}
+	}
+	return refs
+}
+
+// envModulePrefix reports whether an import path is the react-native-dotenv
+// module, named moduleName, or in Expo projects the generated accessor
+// module, and returns the prefix the imported names map to
+func envModulePrefix(path, moduleName, projectType string) (string, bool) {
+	if path == moduleName {
+		return "", true
+	}
+	if projectType != "expo" {
+		return "", false
+	}
+	accessor := strings.TrimSuffix(filepath.ToSlash(ExpoEnvModulePath), ".ts")
+	trimmed := strings.TrimSuffix(strings.TrimSuffix(path, ".ts"), "/index")
+	if strings.HasSuffix(trimmed, strings.TrimPrefix(accessor, "src")) {
+		return ExpoPublicPrefix, true
+	}
+	return "", false
+}
+
+// destructuredName is a key listed between braces and its offset in the source
+type destructuredName struct {
+	key    string
+	offset int
+}
+
+// destructuredNames returns the keys listed in content[start:end], the inside
+// of an import list or destructuring pattern, ignoring aliases and defaults
+func destructuredNames(content string, start, end int) []destructuredName {
+	var names []destructuredName
+	offset := start
+	for _, part := range strings.Split(content[start:end], ",") {
+		trimmed := strings.TrimSpace(part)
+		name := trimmed
+		if idx := strings.IndexAny(name, " \t\n:="); idx >= 0 {
+			name = name[:idx]
+		}
+		name = strings.TrimPrefix(name, "type ")
+		if identifierPattern.MatchString(name) && name != "type" {
+			names = append(names, destructuredName{key: name, offset: offset + strings.Index(part, name)})
+		}
+		offset += len(part) + 1
+	}
+	return names
+}
+
+// Lint cross-references the variables read by the sources with the keys of
+// an env file and reports unused keys, undefined references and, in Expo
+// projects, process.env accesses missing the EXPO_PUBLIC_ prefix. With a
+// schema, which may be nil, keys it marks as build-time-only or server-only
+// are reported when they can reach the app bundle. Issues are sorted by file
+// and line.
+func Lint(envFile *DotenvFile, envFileName string, refs []SourceReference, projectType string, schema *Schema, plugin *config.DotenvPlugin) []LintIssue {
+	var issues []LintIssue
+	used := make(map[string]bool)
+
+	for _, ref := range refs {
+		used[ref.Key] = true
+		if ref.ProcessEnv && (processEnvBuiltins[ref.Key] || isToolingFile(ref.File)) {
+			// Node tooling like app.config.js may read any variable
+			continue
+		}
+
+		issue := LintIssue{Key: ref.Key, File: ref.File, Line: ref.Line}
+		_, defined := envFile.Variables[ref.Key]
+		switch {
+		case projectType == "expo" && ref.ProcessEnv && !strings.HasPrefix(ref.Key, ExpoPublicPrefix):
+			// Only prefixed variables are inlined into the app bundle
+			issue.Kind = LintWrongPrefix
+			issue.Message = fmt.Sprintf("process.env.%s is not inlined by Expo without the %s prefix", ref.Key, ExpoPublicPrefix)
+			prefixed := ExpoPublicPrefix + ref.Key
+			if _, ok := envFile.Variables[prefixed]; ok {
+				used[prefixed] = true
+				issue.Message = fmt.Sprintf("process.env.%s is not inlined by Expo, use process.env.%s", ref.Key, prefixed)
+			}
+		case defined:
+			continue
+		case ref.ProcessEnv && projectType != "expo":
+			// Bare projects read variables through the babel plugin, not process.env
+			continue
+		default:
+			issue.Kind = LintUndefined
+			issue.Message = fmt.Sprintf("%s is not defined in %s", ref.Key, envFileName)
+		}
+		issues = append(issues, issue)
+	}
+
+	for _, key := range envFile.ListKeys() {
+		if _, secret := schema.SecretExposure(key); used[key] || secret {
+			// Secret keys are read by servers and build scripts outside of the sources
+			continue
+		}
+		issues = append(issues, LintIssue{
+			Kind:    LintUnused,
+			Key:     key,
+			File:    envFileName,
+			Line:    envFile.KeyLine(key),
+			Message: fmt.Sprintf("%s is not used by any source file", key),
+		})
+	}
+
+	issues = append(issues, lintExposure(envFile, envFileName, refs, projectType, schema, plugin)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
+
+// isToolingFile reports whether a source runs in Node at build time, like
+// app.config.js or metro.config.js, rather than in the app
+func isToolingFile(file string) bool {
+	return strings.Contains(path.Base(file), ".config.")
+}
+
+// KeyLine returns the line number where key is last defined, or 0 when the
+// file does not define it
+func (e *DotenvFile) KeyLine(key string) int {
+	line, found := 1, 0
+	for _, l := range e.lines {
+		if l.key == key {
+			found = line
+		}
+		line += strings.Count(l.render(), "\n") + 1
+	}
+	return found
+}
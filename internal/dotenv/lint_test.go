@@ -0,0 +1,119 @@
+package dotenv
+
+import (
+	"mirorim-cli/internal/config"
+	"reflect"
+	"testing"
+)
+
+func TestScanSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectType string
+		content     string
+		want        []SourceReference
+	}{
+		{"named import", "bare", "import { API_URL, TOKEN as t } from '@env';\nconsole.log(API_URL)",
+			[]SourceReference{{Key: "API_URL", Line: 1}, {Key: "TOKEN", Line: 1}}},
+		{"multiline import", "bare", "import {\n  API_URL,\n  type Other,\n} from \"@env\";",
+			[]SourceReference{{Key: "API_URL", Line: 2}}},
+		{"require", "bare", "const { API_URL } = require('@env');",
+			[]SourceReference{{Key: "API_URL", Line: 1}}},
+		{"namespace import", "bare", "import * as env from '@env';\nfetch(env.API_URL);\nother.env.NOT_IT;",
+			[]SourceReference{{Key: "API_URL", Line: 2}}},
+		{"other module", "bare", "import { API_URL } from './constants';", nil},
+		{"process.env", "expo", "process.env.EXPO_PUBLIC_A;\nprocess.env['B'];\nconst { C } = process.env;",
+			[]SourceReference{{Key: "EXPO_PUBLIC_A", Line: 1, ProcessEnv: true}, {Key: "B", Line: 2, ProcessEnv: true}, {Key: "C", Line: 3, ProcessEnv: true}}},
+		{"expo accessor module", "expo", "import { API_URL } from '../config/env';\nimport env from '@/config/env/index';\nenv.MODE;",
+			[]SourceReference{{Key: "EXPO_PUBLIC_API_URL", Line: 1}, {Key: "EXPO_PUBLIC_MODE", Line: 3}}},
+		{"bare config module", "bare", "import { API_URL } from '../config/env';", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.want {
+				tt.want[i].File = "App.tsx"
+			}
+			got := scanSource("App.tsx", tt.content, EnvModuleName, tt.projectType)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scanSource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanSourceCustomModuleName(t *testing.T) {
+	got := scanSource("App.tsx", "import { A } from '@env';\nimport { B } from '@vars';", "@vars", "bare")
+	want := []SourceReference{{Key: "B", File: "App.tsx", Line: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scanSource() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLint(t *testing.T) {
+	envFile, err := Parse("EXPO_PUBLIC_USED=1\nEXPO_PUBLIC_UNUSED=2\nEXPO_PUBLIC_PREFIXED=3\nSERVER_TOKEN=4\nEXPO_PUBLIC_SECRET=5\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []SourceReference{
+		{Key: "EXPO_PUBLIC_USED", File: "App.tsx", Line: 1, ProcessEnv: true},
+		{Key: "EXPO_PUBLIC_MISSING", File: "App.tsx", Line: 2, ProcessEnv: true},
+		{Key: "PREFIXED", File: "App.tsx", Line: 3, ProcessEnv: true},
+		{Key: "NODE_ENV", File: "App.tsx", Line: 4, ProcessEnv: true},
+		{Key: "SERVER_TOKEN", File: "app.config.js", Line: 1, ProcessEnv: true},
+		{Key: "SERVER_TOKEN", File: "src/api.ts", Line: 7},
+	}
+	schema := &Schema{Variables: map[string]*VariableSchema{
+		"SERVER_TOKEN": {Type: TypeString, Exposure: ExposureServer},
+		"SECRET":       {Type: TypeString, Exposure: ExposureBuild},
+	}}
+
+	type found struct{ Kind, Key, File string }
+	var got []found
+	for _, issue := range Lint(envFile, ".env", refs, "expo", schema, nil) {
+		got = append(got, found{issue.Kind, issue.Key, issue.File})
+	}
+	want := []found{
+		{LintUnused, "EXPO_PUBLIC_UNUSED", ".env"},
+		{LintExposed, "EXPO_PUBLIC_SECRET", ".env"},
+		{LintUndefined, "EXPO_PUBLIC_MISSING", "App.tsx"},
+		{LintWrongPrefix, "PREFIXED", "App.tsx"},
+		{LintExposed, "SERVER_TOKEN", "src/api.ts"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLintBareExposure(t *testing.T) {
+	envFile, err := Parse("API_URL=1\nSERVER_TOKEN=2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []SourceReference{{Key: "API_URL", File: "App.tsx", Line: 1}}
+	schema := &Schema{Variables: map[string]*VariableSchema{
+		"SERVER_TOKEN": {Type: TypeString, Exposure: ExposureServer},
+	}}
+
+	tests := []struct {
+		name    string
+		plugin  *config.DotenvPlugin
+		exposed bool
+	}{
+		{"no options", nil, false},
+		{"allowlist without the key", &config.DotenvPlugin{Allowlist: []string{"API_URL"}}, false},
+		{"allowlist with the key", &config.DotenvPlugin{Allowlist: []string{"API_URL", "SERVER_TOKEN"}}, true},
+		{"blocklist", &config.DotenvPlugin{Blocklist: []string{"SERVER_TOKEN"}}, false},
+		{"blocklist without the key", &config.DotenvPlugin{Blocklist: []string{"API_URL"}}, true},
+		{"generated allowlist", &config.DotenvPlugin{GenerateAllowlist: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Lint(envFile, ".env", refs, "bare", schema, tt.plugin)
+			exposed := len(issues) == 1 && issues[0].Kind == LintExposed && issues[0].Key == "SERVER_TOKEN"
+			if exposed != tt.exposed || (!tt.exposed && len(issues) > 0) {
+				t.Errorf("Lint() = %+v, want exposed %v", issues, tt.exposed)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package dotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError describes a syntax error in a .env file
+type ParseError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error implements the error interface
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Parse parses the content of a .env file. The returned DotenvFile keeps
+// every line of the input and is not bound to a path until one is set.
+//
+// The supported syntax follows the common dotenv conventions:
+//   - an optional "export " prefix before the key
+//   - unquoted values, where a '#' preceded by whitespace starts a comment
+//   - single-quoted and backtick-quoted values, taken literally
+//   - double-quoted values with \n, \r, \t, \", \\, \$ and \` escapes
+//   - quoted values spanning multiple lines
+//   - ${VAR}, ${VAR:-default} and $VAR references in unquoted and
+//     double-quoted values, expanded by Resolve
+func Parse(content string) (*DotenvFile, error) {
+	p := &parser{
+		src:  []rune(strings.ReplaceAll(content, "\r\n", "\n")),
+		line: 1,
+		col:  1,
+	}
+
+	envFile := &DotenvFile{Variables: make(map[string]string)}
+	for !p.eof() {
+		line, err := p.parseLine()
+		if err != nil {
+			return nil, err
+		}
+		envFile.lines = append(envFile.lines, line)
+		if line.isVariable() {
+			envFile.Variables[line.key] = line.value
+		}
+	}
+	return envFile, nil
+}
+
+// parser is a small hand-written scanner over the runes of a .env file
+type parser struct {
+	src  []rune
+	pos  int
+	line int
+	col  int
+}
+
+// eof reports whether the whole input has been consumed
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// peek returns the current rune without consuming it, or 0 at the end of input
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// next consumes the current rune and keeps track of the line and column
+func (p *parser) next() rune {
+	r := p.src[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+// errorf builds a ParseError at the given position
+func (p *parser) errorf(line, col int, format string, args ...interface{}) error {
+	return &ParseError{Line: line, Column: col, Message: fmt.Sprintf(format, args...)}
+}
+
+// atLineEnd reports whether the current rune ends the line
+func (p *parser) atLineEnd() bool {
+	return p.eof() || p.peek() == '\n'
+}
+
+// skipSpaces consumes spaces and tabs
+func (p *parser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+// skipLine consumes everything up to and including the next newline
+func (p *parser) skipLine() {
+	for !p.atLineEnd() {
+		p.next()
+	}
+	if !p.eof() {
+		p.next()
+	}
+}
+
+// parseLine parses one logical line, which may span several physical lines
+// when it holds a multiline quoted value
+func (p *parser) parseLine() (*envLine, error) {
+	start := p.pos
+	line := &envLine{}
+	defer func() {
+		line.raw = strings.TrimSuffix(string(p.src[start:p.pos]), "\n")
+	}()
+
+	p.skipSpaces()
+	if p.atLineEnd() || p.peek() == '#' {
+		// Blank line or full-line comment
+		p.skipLine()
+		return line, nil
+	}
+
+	// Optional shell-style export prefix
+	if p.hasWord("export") {
+		for i := 0; i < len("export"); i++ {
+			p.next()
+		}
+		p.skipSpaces()
+		line.export = true
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	line.key = key
+
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return nil, p.errorf(p.line, p.col, "expected '=' after key %q", key)
+	}
+	p.next()
+	p.skipSpaces()
+
+	switch p.peek() {
+	case '"', '\'', '`':
+		line.expand = p.peek() == '"'
+		line.value, line.template, err = p.parseQuotedValue()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() == '#' {
+			line.comment = p.parseComment()
+		} else if !p.atLineEnd() {
+			return nil, p.errorf(p.line, p.col, "unexpected character %q after quoted value", p.peek())
+		}
+	default:
+		line.value, line.comment = p.parseUnquotedValue()
+		line.template = strings.ReplaceAll(line.value, `\$`, literalDollar)
+		line.expand = true
+	}
+
+	p.skipLine()
+	return line, nil
+}
+
+// hasWord reports whether the input continues with word followed by whitespace
+func (p *parser) hasWord(word string) bool {
+	end := p.pos + len(word)
+	if end >= len(p.src) || string(p.src[p.pos:end]) != word {
+		return false
+	}
+	return p.src[end] == ' ' || p.src[end] == '\t'
+}
+
+// parseKey parses a variable name made of letters, digits, '_', '.' and '-'
+func (p *parser) parseKey() (string, error) {
+	var key strings.Builder
+	for !p.atLineEnd() {
+		r := p.peek()
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isTail := (r >= '0' && r <= '9') || r == '.' || r == '-'
+		if !isLetter && !(isTail && key.Len() > 0) {
+			break
+		}
+		key.WriteRune(p.next())
+	}
+
+	if key.Len() == 0 {
+		return "", p.errorf(p.line, p.col, "invalid character %q at start of key", p.peek())
+	}
+	return key.String(), nil
+}
+
+// parseQuotedValue parses a value enclosed in single, double or backtick quotes.
+// Only double-quoted values interpret escape sequences and references, for
+// which the template used by interpolation is returned as well.
+func (p *parser) parseQuotedValue() (string, string, error) {
+	startLine, startCol := p.line, p.col
+	quote := p.next()
+
+	var value, template strings.Builder
+	for !p.eof() {
+		r := p.next()
+		if r == quote {
+			if quote != '"' {
+				return value.String(), "", nil
+			}
+			return value.String(), template.String(), nil
+		}
+		if r == '\\' && quote == '"' && !p.eof() {
+			escaped := p.next()
+			value.WriteString(unescape(escaped))
+			if escaped == '$' {
+				template.WriteString(literalDollar)
+			} else {
+				template.WriteString(unescape(escaped))
+			}
+			continue
+		}
+		value.WriteRune(r)
+		template.WriteRune(r)
+	}
+	return "", "", p.errorf(startLine, startCol, "unterminated quoted value, missing closing %c", quote)
+}
+
+// unescape returns the text for a backslash escape in a double-quoted value.
+// Unknown escapes are kept as written.
+func unescape(r rune) string {
+	switch r {
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case '"', '\\', '$', '`':
+		return string(r)
+	default:
+		return "\\" + string(r)
+	}
+}
+
+// parseUnquotedValue parses a bare value up to the end of the line. A '#'
+// at the start of the value or preceded by whitespace begins a comment.
+func (p *parser) parseUnquotedValue() (string, string) {
+	var value strings.Builder
+	for !p.atLineEnd() {
+		if p.peek() == '#' {
+			current := value.String()
+			if current == "" || strings.HasSuffix(current, " ") || strings.HasSuffix(current, "\t") {
+				return strings.TrimSpace(current), p.parseComment()
+			}
+		}
+		value.WriteRune(p.next())
+	}
+	return strings.TrimSpace(value.String()), ""
+}
+
+// parseComment consumes an inline comment and returns it without the leading '#'
+func (p *parser) parseComment() string {
+	p.next()
+	var comment strings.Builder
+	for !p.atLineEnd() {
+		comment.WriteRune(p.next())
+	}
+	return comment.String()
+}
+
+// FormatValue quotes a value so that Parse reads it back unchanged. Plain
+// values are written bare, values without single quotes or newlines are
+// single-quoted, and everything else is double-quoted with escapes.
+func FormatValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	if !strings.ContainsAny(value, " \t\n\r#\"'`\\$") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`$`, `\$`,
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,185 @@
+package dotenv
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		comment string
+	}{
+		{"unquoted", "KEY=value", "KEY", "value", ""},
+		{"empty", "KEY=", "KEY", "", ""},
+		{"spaces around", "  KEY = value  ", "KEY", "value", ""},
+		{"export", "export KEY=value", "KEY", "value", ""},
+		{"export as key", "export=value", "export", "value", ""},
+		{"inline comment", "KEY=value # note", "KEY", "value", " note"},
+		{"hash inside value", "KEY=a#b", "KEY", "a#b", ""},
+		{"comment only", "KEY=# note", "KEY", "", " note"},
+		{"single quoted", `KEY='a "b" \n $C'`, "KEY", `a "b" \n $C`, ""},
+		{"backtick quoted", "KEY=`it's`", "KEY", "it's", ""},
+		{"double quoted escapes", `KEY="a\nb\t\"c\" \\ \$d \q"`, "KEY", "a\nb\t\"c\" \\ $d \\q", ""},
+		{"quoted with comment", `KEY="a # b" # c`, "KEY", "a # b", " c"},
+		{"multiline double", "KEY=\"line 1\nline 2\"", "KEY", "line 1\nline 2", ""},
+		{"multiline single", "KEY='line 1\nline 2'", "KEY", "line 1\nline 2", ""},
+		{"crlf", "KEY=value\r\n", "KEY", "value", ""},
+		{"dotted key", "api.url-2=x", "api.url-2", "x", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile, err := Parse(tt.content)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.content, err)
+			}
+			if got := envFile.Variables[tt.key]; got != tt.value {
+				t.Errorf("value of %s = %q, want %q", tt.key, got, tt.value)
+			}
+			if got := envFile.lines[0].comment; got != tt.comment {
+				t.Errorf("comment = %q, want %q", got, tt.comment)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+	}{
+		{"missing equals", "KEY value", 1, 5},
+		{"invalid key", "A=1\n1KEY=x", 2, 1},
+		{"unterminated quote", "A=1\nKEY=\"value\nB=2", 2, 5},
+		{"text after quote", "KEY='a' b", 1, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.content)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want a ParseError", tt.content, err)
+			}
+			if parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("error at %d:%d, want %d:%d (%v)", parseErr.Line, parseErr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestParseLastDefinitionWins(t *testing.T) {
+	envFile, err := Parse("KEY=1\nKEY=2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := envFile.Variables["KEY"]; got != "2" {
+		t.Errorf("KEY = %q, want 2", got)
+	}
+	if keys := envFile.ListKeys(); len(keys) != 1 {
+		t.Errorf("ListKeys() = %v, want a single key", keys)
+	}
+}
+
+func TestRenderRoundTrip(t *testing.T) {
+	contents := []string{
+		"",
+		"KEY=value\n",
+		"# Section\n\n  KEY = value   # note\nexport OTHER='x y'\n",
+		"MULTI=\"line 1\nline 2\"\n# trailing comment\n",
+		"A=${B:-default}\nB=$A\\$C\n",
+		"\n\n\n",
+	}
+
+	for _, content := range contents {
+		envFile, err := Parse(content)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", content, err)
+		}
+		if got := envFile.Render(); got != content {
+			t.Errorf("Render() = %q, want %q", got, content)
+		}
+	}
+}
+
+func TestUpdatePreservesLayout(t *testing.T) {
+	envFile, err := Parse("# API\nexport URL=http://a # prod\n\n# Other\nX=1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envFile.AddOrUpdateKey("url", "http://b")
+	envFile.AddOrUpdateKeyInSection("token", "t", "API")
+	envFile.AddOrUpdateKeyInSection("NEW", "n", "Misc")
+	envFile.RemoveKey("x")
+
+	want := "# API\nexport URL=http://b # prod\nTOKEN=t\n\n# Other\n\n# Misc\nNEW=n\n"
+	if got := envFile.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestMixedCaseKeys(t *testing.T) {
+	envFile, err := Parse("apiKey=1\nB=2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envFile.AddOrUpdateKey("apiKey", "9")
+	if got, want := envFile.Render(), "apiKey=9\nB=2\n"; got != want {
+		t.Errorf("after updating apiKey, Render() = %q, want %q", got, want)
+	}
+	envFile.AddOrUpdateKey("new", "n")
+	envFile.AddOrUpdateKey("b", "3")
+	if got, want := envFile.Render(), "apiKey=9\nB=3\nNEW=n\n"; got != want {
+		t.Errorf("after adding new and updating b, Render() = %q, want %q", got, want)
+	}
+
+	envFile.RemoveKey("apiKey")
+	envFile.RemoveKey("new")
+	if got, want := envFile.Render(), "B=3\n"; got != want {
+		t.Errorf("after removing apiKey and new, Render() = %q, want %q", got, want)
+	}
+	if keys := envFile.ListKeys(); len(keys) != 1 || keys[0] != "B" {
+		t.Errorf("ListKeys() = %v, want [B]", keys)
+	}
+}
+
+func TestFormatValueRoundTrip(t *testing.T) {
+	values := []string{
+		"",
+		"plain",
+		"with space",
+		"a#b",
+		"it's",
+		`say "hi"`,
+		"back`tick",
+		"line 1\nline 2",
+		`C:\path\$HOME`,
+		"it's\n$HOME",
+		"\r\n\t",
+	}
+
+	for _, value := range values {
+		content := "KEY=" + FormatValue(value)
+		envFile, err := Parse(content)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", content, err)
+		}
+		if got := envFile.Variables["KEY"]; got != value {
+			t.Errorf("FormatValue(%q) reads back as %q", value, got)
+		}
+		resolved, err := envFile.Resolve(false)
+		if err != nil {
+			t.Fatalf("Resolve(%q) failed: %v", content, err)
+		}
+		if got := resolved["KEY"]; got != value {
+			t.Errorf("FormatValue(%q) resolves to %q", value, got)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package dotenv
+
+import (
+	"fmt"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FileChange is the new content of a file touched by a rename
+type FileChange struct {
+	Path       string // relative to the project
+	OldContent string
+	NewContent string
+}
+
+// RenameKey renames every definition of oldKey and the references to it in
+// other values, keeping the formatting of the file. It fails when newKey is
+// already defined.
+func (e *DotenvFile) RenameKey(oldKey, newKey string) error {
+	if _, ok := e.Variables[newKey]; ok {
+		return fmt.Errorf("%s is already defined", newKey)
+	}
+
+	reference := regexp.MustCompile(`(^|[^\\])\$(\{)?` + regexp.QuoteMeta(oldKey) + `([^A-Za-z0-9_]|$)`)
+	var content strings.Builder
+	for _, line := range e.lines {
+		text := line.render()
+		if line.isVariable() {
+			// The key follows the optional export prefix
+			start := len(text) - len(strings.TrimLeft(text, " \t"))
+			if line.export {
+				start = strings.Index(text, "export") + len("export")
+				start += len(text[start:]) - len(strings.TrimLeft(text[start:], " \t"))
+			}
+			if line.key == oldKey {
+				text = text[:start] + newKey + text[start+len(oldKey):]
+			}
+			if line.expand {
+				// ${OLD}, ${OLD:-default} and $OLD references in the value
+				eq := strings.Index(text, "=")
+				text = text[:eq] + replaceAllRepeated(reference, text[eq:], "${1}$$${2}"+newKey+"${3}")
+			}
+		}
+		content.WriteString(text + "\n")
+	}
+
+	renamed, err := Parse(content.String())
+	if err != nil {
+		return fmt.Errorf("failed to rename %s: %v", oldKey, err)
+	}
+	e.lines = renamed.lines
+	e.Variables = renamed.Variables
+	return nil
+}
+
+// replaceAllRepeated applies a replacement until nothing matches anymore, for
+// patterns whose matches consume the character the next match starts with
+func replaceAllRepeated(pattern *regexp.Regexp, s, replacement string) string {
+	for {
+		replaced := pattern.ReplaceAllString(s, replacement)
+		if replaced == s {
+			return s
+		}
+		s = replaced
+	}
+}
+
+// RenameInSource rewrites the reads of a variable in a JavaScript or
+// TypeScript source: named and namespace imports from the react-native-dotenv
+// module named moduleName, in Expo projects imports from the generated
+// accessor module, and process.env accesses. Identifiers bound by a renamed
+// import are renamed in the whole file, but not strings, object keys or
+// properties of other objects.
+func RenameInSource(content, oldKey, newKey, moduleName, projectType string) (string, error) {
+	type rename struct{ from, to string }
+	var bindings []rename
+	edits := make(map[int]string)
+
+	// Named imports list the keys, without the prefix for the accessor module
+	for _, m := range namedImportPattern.FindAllStringSubmatchIndex(content, -1) {
+		prefix, ok := envModulePrefix(content[m[4]:m[5]], moduleName, projectType)
+		if !ok || !strings.HasPrefix(oldKey, prefix) || !strings.HasPrefix(newKey, prefix) {
+			continue
+		}
+		from, to := strings.TrimPrefix(oldKey, prefix), strings.TrimPrefix(newKey, prefix)
+		for _, name := range destructuredNames(content, m[2], m[3]) {
+			if name.key != from {
+				continue
+			}
+			edits[name.offset] = to
+			// Without an alias, the import binds the key itself
+			if !importAliasPattern.MatchString(content[name.offset+len(from) : m[3]]) {
+				bindings = append(bindings, rename{from, to})
+			}
+		}
+	}
+
+	if len(bindings) > 0 {
+		tokens, err := tokenize(content)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range bindings {
+			for offset, replacement := range identifierEdits(tokens, r.from, r.to) {
+				edits[offset] = replacement
+			}
+		}
+	}
+
+	// Apply the edits from the end so the offsets stay valid
+	offsets := make([]int, 0, len(edits))
+	for offset := range edits {
+		offsets = append(offsets, offset)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, offset := range offsets {
+		end := offset
+		for end < len(content) && (isIdentStart(content[end]) || (content[end] >= '0' && content[end] <= '9')) {
+			end++
+		}
+		content = content[:offset] + edits[offset] + content[end:]
+	}
+
+	// Namespace imports are followed by member accesses
+	for _, m := range namespaceImportPattern.FindAllStringSubmatchIndex(content, -1) {
+		prefix, ok := envModulePrefix(content[m[4]:m[5]], moduleName, projectType)
+		if !ok || !strings.HasPrefix(oldKey, prefix) || !strings.HasPrefix(newKey, prefix) {
+			continue
+		}
+		alias := regexp.QuoteMeta(content[m[2]:m[3]])
+		access := regexp.MustCompile(`(^|[^.\w$])(` + alias + `)\.` + regexp.QuoteMeta(strings.TrimPrefix(oldKey, prefix)) + `\b`)
+		content = access.ReplaceAllString(content, "${1}${2}."+strings.TrimPrefix(newKey, prefix))
+	}
+
+	processEnv := regexp.MustCompile(`process\.env(?:\.` + regexp.QuoteMeta(oldKey) + `\b|\[(\s*)(['"])` + regexp.QuoteMeta(oldKey) + `(['"])(\s*)\])`)
+	content = processEnv.ReplaceAllStringFunc(content, func(match string) string {
+		return strings.Replace(match, oldKey, newKey, 1)
+	})
+	return content, nil
+}
+
+// importAliasPattern matches the rest of an import specifier renamed with as
+var importAliasPattern = regexp.MustCompile(`^\s+as\b`)
+
+// identifierEdits returns the replacements, by offset, that rename the
+// identifier from to to. Strings, properties like other.FROM and object keys
+// are left alone; shorthand properties keep their key, { FROM } becoming
+// { FROM: TO }, and export lists keep the exported name.
+func identifierEdits(tokens []token, from, to string) map[int]string {
+	edits := make(map[int]string)
+	var braces []int
+	for i, t := range tokens {
+		switch {
+		case t.isOpening():
+			braces = append(braces, i)
+			continue
+		case t.isClosing():
+			if len(braces) > 0 {
+				braces = braces[:len(braces)-1]
+			}
+			continue
+		case t.kind != identToken || t.text != from:
+			continue
+		case i > 0 && tokens[i-1].is("."):
+			continue
+		}
+
+		if len(braces) == 0 || !tokens[braces[len(braces)-1]].is("{") || !(tokens[i-1].is("{") || tokens[i-1].is(",")) || i+1 == len(tokens) {
+			edits[t.start] = to
+			continue
+		}
+		next := tokens[i+1]
+		switch {
+		case next.is(":") || next.is("("):
+			// An object key or a method
+		case next.is(",") || next.is("}"):
+			switch moduleList(tokens, braces[len(braces)-1]) {
+			case "import":
+				edits[t.start] = to
+			case "export":
+				edits[t.start] = to + " as " + from
+			default:
+				edits[t.start] = from + ": " + to
+			}
+		default:
+			edits[t.start] = to
+		}
+	}
+	return edits
+}
+
+// moduleList returns "import" or "export" when the brace at open starts the
+// list of an import or export statement, "" for objects and blocks
+func moduleList(tokens []token, open int) string {
+	i := open - 1
+	if i >= 0 && tokens[i].is("type") {
+		i--
+	}
+	if i >= 1 && tokens[i].is(",") && tokens[i-1].kind == identToken {
+		// import Default, { ... }
+		i -= 2
+	}
+	if i >= 0 && (tokens[i].is("import") || tokens[i].is("export")) {
+		return tokens[i].text
+	}
+	return ""
+}
+
+// PlanRename computes the changes that rename a variable across the env
+// files, the schema and the sources of the project, without writing anything.
+// Encrypted files cannot be rewritten and are left for env encrypt.
+func PlanRename(projectPath, projectType, oldKey, newKey string) ([]FileChange, error) {
+	var changes []FileChange
+
+	entries, err := os.ReadDir(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (name != ".env" && !strings.HasPrefix(name, ".env.")) || strings.HasSuffix(name, EncryptedSuffix) {
+			continue
+		}
+
+		envFile, err := ReadEnvFile(filepath.Join(projectPath, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		before := envFile.Render()
+		if err := envFile.RenameKey(oldKey, newKey); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if after := envFile.Render(); after != before {
+			changes = append(changes, FileChange{Path: name, OldContent: before, NewContent: after})
+		}
+	}
+
+	if HasSchema(projectPath) {
+		schema, err := LoadSchema(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		if variable, ok := schema.Variables[oldKey]; ok {
+			if _, exists := schema.Variables[newKey]; exists {
+				return nil, fmt.Errorf("%s: %s is already declared", SchemaFileName, newKey)
+			}
+			before, err := os.ReadFile(filepath.Join(projectPath, SchemaFileName))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", SchemaFileName, err)
+			}
+			delete(schema.Variables, oldKey)
+			schema.Variables[newKey] = variable
+			after, err := renderSchema(schema)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, FileChange{Path: SchemaFileName, OldContent: string(before), NewContent: after})
+		}
+	}
+
+	moduleName := ModuleName(projectPath)
+	err = walkSourceFiles(projectPath, func(rel, content string) error {
+		renamed, err := RenameInSource(content, oldKey, newKey, moduleName, projectType)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", rel, err)
+		}
+		if renamed != content {
+			changes = append(changes, FileChange{Path: rel, OldContent: content, NewContent: renamed})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan sources: %v", err)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// ApplyChanges writes the new content of every change, keeping file modes
+func ApplyChanges(projectPath string, changes []FileChange) error {
+	// Snapshot the env files together so the whole change can be restored
+	var envFiles []string
+	for _, change := range changes {
+		if !strings.Contains(change.Path, "/") && (change.Path == ".env" || isEnvironmentFile(change.Path)) {
+			envFiles = append(envFiles, change.Path)
+		}
+	}
+	if _, err := CreateSnapshot(projectPath, "rename", envFiles); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		path := filepath.Join(projectPath, filepath.FromSlash(change.Path))
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", change.Path, err)
+		}
+		err = fsutil.WriteFile(path, []byte(change.NewContent), info.Mode().Perm())
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %v", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// LineDiff renders the lines that differ between the old and new content of
+// a change, in a unified diff style. Renames rewrite lines in place, so lines
+// are compared one to one when the line count is unchanged; otherwise the
+// differing block is shown as a whole.
+func (c FileChange) LineDiff() string {
+	oldLines := strings.Split(c.OldContent, "\n")
+	newLines := strings.Split(c.NewContent, "\n")
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- a/%s\n+++ b/%s\n", c.Path, c.Path)
+
+	if len(oldLines) != len(newLines) {
+		// Skip the common lines around the changed block
+		start := 0
+		for start < len(oldLines) && start < len(newLines) && oldLines[start] == newLines[start] {
+			start++
+		}
+		oldEnd, newEnd := len(oldLines), len(newLines)
+		for oldEnd > start && newEnd > start && oldLines[oldEnd-1] == newLines[newEnd-1] {
+			oldEnd--
+			newEnd--
+		}
+		fmt.Fprintf(&diff, "@@ line %d @@\n", start+1)
+		for _, line := range oldLines[start:oldEnd] {
+			fmt.Fprintf(&diff, "-%s\n", line)
+		}
+		for _, line := range newLines[start:newEnd] {
+			fmt.Fprintf(&diff, "+%s\n", line)
+		}
+		return diff.String()
+	}
+
+	for i := range oldLines {
+		if oldLines[i] != newLines[i] {
+			fmt.Fprintf(&diff, "@@ line %d @@\n-%s\n+%s\n", i+1, oldLines[i], newLines[i])
+		}
+	}
+	return diff.String()
+}
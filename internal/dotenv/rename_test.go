@@ -0,0 +1,141 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameKey(t *testing.T) {
+	envFile, err := Parse("# API\nexport API_URL=http://a # prod\nAPI_URL_V2=$API_URL/v2\nFULL=${API_URL:-x}\nESCAPED=\\$API_URL\nLITERAL='$API_URL'\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := envFile.RenameKey("API_URL", "BASE_URL"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# API\nexport BASE_URL=http://a # prod\nAPI_URL_V2=$BASE_URL/v2\nFULL=${BASE_URL:-x}\nESCAPED=\\$API_URL\nLITERAL='$API_URL'\n"
+	if got := envFile.Render(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if _, ok := envFile.Variables["API_URL"]; ok {
+		t.Error("API_URL is still defined")
+	}
+	if err := envFile.RenameKey("FULL", "BASE_URL"); err == nil {
+		t.Error("renaming to a defined key succeeded")
+	}
+}
+
+func TestRenameInSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectType string
+		prefix      string
+		content     string
+		want        string
+	}{
+		{
+			"named import",
+			"bare", "",
+			"import { API_URL, OTHER } from '@env';\nfetch(API_URL + '/users');\n",
+			"import { BASE_URL, OTHER } from '@env';\nfetch(BASE_URL + '/users');\n",
+		},
+		{
+			"strings and keys are kept",
+			"bare", "",
+			"import { API_URL } from '@env';\nconst labels = { 'API_URL': 'x', API_URL: API_URL };\nlog(`API_URL`, \"API_URL\", other.API_URL);\n",
+			"import { BASE_URL } from '@env';\nconst labels = { 'API_URL': 'x', API_URL: BASE_URL };\nlog(`API_URL`, \"API_URL\", other.API_URL);\n",
+		},
+		{
+			"shorthand property",
+			"bare", "",
+			"import { API_URL } from '@env';\nexport const config = { API_URL, timeout };\nexport { API_URL };\n",
+			"import { BASE_URL } from '@env';\nexport const config = { API_URL: BASE_URL, timeout };\nexport { BASE_URL as API_URL };\n",
+		},
+		{
+			"ternary",
+			"bare", "",
+			"import { API_URL } from '@env';\nconst url = dev ? API_URL : prod;\nconst other = { a: dev ? API_URL : prod };\n",
+			"import { BASE_URL } from '@env';\nconst url = dev ? BASE_URL : prod;\nconst other = { a: dev ? BASE_URL : prod };\n",
+		},
+		{
+			"aliased import",
+			"bare", "",
+			"import { API_URL as url } from '@env';\nconst API_URL = url;\n",
+			"import { BASE_URL as url } from '@env';\nconst API_URL = url;\n",
+		},
+		{
+			"namespace import",
+			"bare", "",
+			"import * as env from '@env';\nfetch(env.API_URL);\n",
+			"import * as env from '@env';\nfetch(env.BASE_URL);\n",
+		},
+		{
+			"other module",
+			"bare", "",
+			"import { API_URL } from './constants';\nfetch(API_URL);\n",
+			"import { API_URL } from './constants';\nfetch(API_URL);\n",
+		},
+		{
+			"process.env",
+			"expo", "",
+			"fetch(process.env.API_URL + process.env['API_URL']);\n",
+			"fetch(process.env.BASE_URL + process.env['BASE_URL']);\n",
+		},
+		{
+			"accessor module",
+			"expo", ExpoPublicPrefix,
+			"import { API_URL } from '@/config/env';\nfetch(API_URL);\n",
+			"import { BASE_URL } from '@/config/env';\nfetch(BASE_URL);\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenameInSource(tt.content, tt.prefix+"API_URL", tt.prefix+"BASE_URL", "@env", tt.projectType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RenameInSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRename(t *testing.T) {
+	project := t.TempDir()
+	files := map[string]string{
+		".env":             "API_URL=http://a\n",
+		".env.staging":     "API_URL=http://b\nOTHER=$API_URL\n",
+		"src/api.js":       "import { API_URL } from '@env';\nfetch(API_URL);\n",
+		"src/unrelated.js": "const API_URL = 'x';\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := PlanRename(project, "bare", "API_URL", "BASE_URL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".env", ".env.staging", "src/api.js"}
+	if len(changes) != len(want) {
+		t.Fatalf("PlanRename() changes %d files, want %v", len(changes), want)
+	}
+	for i, path := range want {
+		if changes[i].Path != path {
+			t.Errorf("change %d is for %s, want %s", i, changes[i].Path, path)
+		}
+	}
+	if got := changes[1].NewContent; got != "BASE_URL=http://b\nOTHER=$BASE_URL\n" {
+		t.Errorf(".env.staging becomes %q", got)
+	}
+}
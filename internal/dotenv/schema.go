@@ -0,0 +1,294 @@
+package dotenv
+
+import (
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/fsutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaFileName is the name of the env schema file managed by the CLI
+const SchemaFileName = "env.schema.json"
+
+// Supported variable types in the env schema
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeURL     = "url"
+	TypeEnum    = "enum"
+)
+
+// SchemaTypes lists the supported variable types
+var SchemaTypes = []string{TypeString, TypeNumber, TypeBoolean, TypeURL, TypeEnum}
+
+// VariableSchema describes a single environment variable
+type VariableSchema struct {
+	Type        string   `json:"type"`
+	Values      []string `json:"values,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Default     *string  `json:"default,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Exposure    string   `json:"exposure,omitempty"`
+}
+
+// Schema represents the structure of env.schema.json
+type Schema struct {
+	Variables map[string]*VariableSchema `json:"variables"`
+}
+
+// ValidationError describes a variable that does not match its schema
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// HasSchema reports whether the project has an env.schema.json file
+func HasSchema(projectPath string) bool {
+	_, err := os.Stat(filepath.Join(projectPath, SchemaFileName))
+	return err == nil
+}
+
+// LoadSchema loads env.schema.json from the project
+func LoadSchema(projectPath string) (*Schema, error) {
+	data, err := os.ReadFile(filepath.Join(projectPath, SchemaFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", SchemaFileName, err)
+	}
+
+	schema := &Schema{}
+	err = json.Unmarshal(data, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", SchemaFileName, err)
+	}
+	if schema.Variables == nil {
+		schema.Variables = make(map[string]*VariableSchema)
+	}
+
+	for key, variable := range schema.Variables {
+		if err := variable.check(); err != nil {
+			return nil, fmt.Errorf("invalid schema for %s: %v", key, err)
+		}
+	}
+	return schema, nil
+}
+
+// loadProjectSchema loads env.schema.json, or returns nil when the project has none
+func loadProjectSchema(projectPath string) (*Schema, error) {
+	if !HasSchema(projectPath) {
+		return nil, nil
+	}
+	return LoadSchema(projectPath)
+}
+
+// SaveSchema writes env.schema.json with keys in sorted order
+func SaveSchema(projectPath string, schema *Schema) error {
+	content, err := renderSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	err = fsutil.WriteFile(filepath.Join(projectPath, SchemaFileName), []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", SchemaFileName, err)
+	}
+	return nil
+}
+
+// renderSchema returns the content of env.schema.json for a schema
+func renderSchema(schema *Schema) (string, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize %s: %v", SchemaFileName, err)
+	}
+	return string(data) + "\n", nil
+}
+
+// InferSchema builds a schema from the values of an env file, guessing the
+// type of each variable. Every variable present in the file is required.
+func InferSchema(envFile *DotenvFile) *Schema {
+	schema := &Schema{Variables: make(map[string]*VariableSchema)}
+	for _, key := range envFile.ListKeys() {
+		schema.Variables[key] = &VariableSchema{
+			Type:     inferType(envFile.Variables[key]),
+			Required: true,
+		}
+	}
+	return schema
+}
+
+// inferType guesses the schema type of a value
+func inferType(value string) string {
+	if isBoolean(value) {
+		return TypeBoolean
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return TypeNumber
+	}
+	if isURL(value) {
+		return TypeURL
+	}
+	return TypeString
+}
+
+// isBoolean reports whether value is a boolean as the schema accepts it.
+// Only the lower case words are, as the generated types compare with them.
+func isBoolean(value string) bool {
+	return value == "true" || value == "false"
+}
+
+// isURL reports whether value is an absolute URL
+func isURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// check validates the definition of a variable itself
+func (v *VariableSchema) check() error {
+	known := false
+	for _, t := range SchemaTypes {
+		if v.Type == t {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown type %q, expected one of %s", v.Type, strings.Join(SchemaTypes, ", "))
+	}
+	if v.Type == TypeEnum && len(v.Values) == 0 {
+		return fmt.Errorf("enum type requires at least one value")
+	}
+	if v.Type != TypeEnum && len(v.Values) > 0 {
+		return fmt.Errorf("values are only allowed for the enum type")
+	}
+	if v.Exposure != "" && v.Exposure != ExposurePublic && !IsSecretExposure(v.Exposure) {
+		return fmt.Errorf("unknown exposure %q, expected one of %s", v.Exposure, strings.Join(Exposures, ", "))
+	}
+	if v.Default != nil {
+		if err := v.checkValue(*v.Default); err != nil {
+			return fmt.Errorf("invalid default: %v", err)
+		}
+	}
+	return nil
+}
+
+// checkValue validates a value against the variable type
+func (v *VariableSchema) checkValue(value string) error {
+	switch v.Type {
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+	case TypeBoolean:
+		if !isBoolean(value) {
+			return fmt.Errorf("%q is not a boolean, expected true or false", value)
+		}
+	case TypeURL:
+		if !isURL(value) {
+			return fmt.Errorf("%q is not an absolute URL", value)
+		}
+	case TypeEnum:
+		for _, allowed := range v.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(v.Values, ", "))
+	}
+	return nil
+}
+
+// Validate checks the variables of an env file against the schema. Keys the
+// schema does not declare are accepted, and so are missing required keys that
+// have a default. Errors are sorted by key.
+func (s *Schema) Validate(envFile *DotenvFile) []ValidationError {
+	var errors []ValidationError
+	for key, variable := range s.Variables {
+		value, ok := envFile.Variables[key]
+		if !ok || value == "" {
+			if variable.Required && variable.Default == nil {
+				errors = append(errors, ValidationError{Key: key, Message: "required variable is missing"})
+			}
+			continue
+		}
+		if err := variable.checkValue(value); err != nil {
+			errors = append(errors, ValidationError{Key: key, Message: err.Error()})
+		}
+	}
+
+	sort.Slice(errors, func(i, j int) bool {
+		return errors[i].Key < errors[j].Key
+	})
+	return errors
+}
+
+// SetVariable adds or replaces the definition of a variable
+func (s *Schema) SetVariable(key string, variable *VariableSchema) error {
+	if err := variable.check(); err != nil {
+		return err
+	}
+	s.Variables[key] = variable
+	return nil
+}
+
+// TypeScriptType returns the TypeScript type of a variable. react-native-dotenv
+// always provides strings, so numbers and booleans are typed as the strings
+// that represent them.
+func (v *VariableSchema) TypeScriptType() string {
+	var tsType string
+	switch v.Type {
+	case TypeNumber:
+		tsType = "`${number}`"
+	case TypeBoolean:
+		tsType = `"true" | "false"`
+	case TypeEnum:
+		values := make([]string, len(v.Values))
+		for i, value := range v.Values {
+			values[i] = strconv.Quote(value)
+		}
+		tsType = strings.Join(values, " | ")
+	default:
+		tsType = "string"
+	}
+
+	// Defaults are documented but not applied by the babel plugin
+	if !v.Required {
+		tsType += " | undefined"
+	}
+	return tsType
+}
+
+// JSDoc returns the documentation comment of a variable, indented by indent,
+// or an empty string when there is nothing to document
+func (v *VariableSchema) JSDoc(indent string) string {
+	var lines []string
+	if v.Description != "" {
+		lines = append(lines, v.Description)
+	}
+	if v.Type == TypeURL {
+		lines = append(lines, "@format url")
+	}
+	if v.Default != nil {
+		lines = append(lines, "@default "+strconv.Quote(*v.Default))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var doc strings.Builder
+	doc.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		doc.WriteString(indent + " * " + line + "\n")
+	}
+	doc.WriteString(indent + " */\n")
+	return doc.String()
+}
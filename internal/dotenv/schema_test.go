@@ -0,0 +1,85 @@
+package dotenv
+
+import "testing"
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"true", TypeBoolean},
+		{"false", TypeBoolean},
+		{"True", TypeString},
+		{"TRUE", TypeString},
+		{"T", TypeString},
+		{"1", TypeNumber},
+		{"0", TypeNumber},
+		{"-2.5", TypeNumber},
+		{"https://api.example.com/v1", TypeURL},
+		{"example.com", TypeString},
+		{"", TypeString},
+	}
+
+	for _, tt := range tests {
+		if got := inferType(tt.value); got != tt.want {
+			t.Errorf("inferType(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestInferredSchemaValidates(t *testing.T) {
+	envFile, err := Parse("DEBUG=True\nFLAG=false\nPORT=8080\nAPI_URL=https://api.example.com\nNAME=demo\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := InferSchema(envFile)
+	if errors := schema.Validate(envFile); len(errors) > 0 {
+		t.Errorf("Validate() on the inferred schema = %v, want no errors", errors)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	port := "8080"
+	schema := &Schema{Variables: map[string]*VariableSchema{
+		"API_URL": {Type: TypeURL, Required: true},
+		"DEBUG":   {Type: TypeBoolean},
+		"MODE":    {Type: TypeEnum, Values: []string{"dev", "prod"}, Required: true},
+		"PORT":    {Type: TypeNumber, Required: true, Default: &port},
+		"TOKEN":   {Type: TypeString, Required: true},
+	}}
+	envFile, err := Parse("API_URL=not a url\nDEBUG=yes\nMODE=prod\nTOKEN=\nEXTRA=1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errors := schema.Validate(envFile)
+	want := []string{"API_URL", "DEBUG", "TOKEN"}
+	if len(errors) != len(want) {
+		t.Fatalf("Validate() = %v, want errors for %v", errors, want)
+	}
+	for i, key := range want {
+		if errors[i].Key != key {
+			t.Errorf("error %d is for %s, want %s", i, errors[i].Key, key)
+		}
+	}
+}
+
+func TestSetVariableChecksDefinition(t *testing.T) {
+	schema := &Schema{Variables: make(map[string]*VariableSchema)}
+	invalid := "maybe"
+	for _, variable := range []*VariableSchema{
+		{Type: "date"},
+		{Type: TypeEnum},
+		{Type: TypeString, Values: []string{"a"}},
+		{Type: TypeBoolean, Default: &invalid},
+		{Type: TypeString, Exposure: "client"},
+	} {
+		if err := schema.SetVariable("KEY", variable); err == nil {
+			t.Errorf("SetVariable(%+v) succeeded, want an error", variable)
+		}
+	}
+	if len(schema.Variables) != 0 {
+		t.Errorf("invalid definitions were stored: %v", schema.Variables)
+	}
+}
@@ -0,0 +1,65 @@
+package dotenv
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SkippedDirs are directories that never hold project sources: dependencies,
+// native build output, tool caches and the backups of the CLI
+var SkippedDirs = map[string]bool{
+	"node_modules": true,
+	".git":         true,
+	".expo":        true,
+	"Pods":         true,
+	"build":        true,
+	"dist":         true,
+	"coverage":     true,
+	"vendor":       true,
+	".gradle":      true,
+	"DerivedData":  true,
+	".mirorim-cli": true,
+}
+
+// sourceExtensions are the JavaScript and TypeScript files scanned for env usage
+var sourceExtensions = map[string]bool{
+	".js":  true,
+	".jsx": true,
+	".ts":  true,
+	".tsx": true,
+	".mjs": true,
+	".cjs": true,
+}
+
+// walkSourceFiles calls fn with the path relative to the project and the
+// content of every JavaScript and TypeScript source. Skipped directories,
+// native projects and files generated by the CLI are left out.
+func walkSourceFiles(projectPath string, fn func(rel string, content string) error) error {
+	return filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(projectPath, path)
+		if entry.IsDir() {
+			if path != projectPath && (SkippedDirs[entry.Name()] || rel == "ios" || rel == "android") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !sourceExtensions[filepath.Ext(path)] || strings.HasSuffix(path, ".d.ts") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content := string(data)
+		if strings.HasPrefix(content, generatedHeader) {
+			return nil
+		}
+		return fn(filepath.ToSlash(rel), content)
+	})
+}
@@ -0,0 +1,263 @@
+package dotenv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mirorim-cli/internal/config"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// DotenvPackage is the package providing the @env module to Bare React Native projects
+const DotenvPackage = "react-native-dotenv"
+
+// initBackupDir keeps the copies of the files modified by 'env init'
+var initBackupDir = filepath.Join(".mirorim-cli", "init")
+
+// recordModification runs modify, which changes the project file name, and
+// records the change with a copy of the original file so it can be undone
+func recordModification(projectPath, name string, modify func() error) error {
+	path := filepath.Join(projectPath, name)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", name, err)
+	}
+
+	if err := modify(); err != nil {
+		return err
+	}
+
+	modified, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	if string(modified) == string(original) {
+		return nil
+	}
+
+	copyName := filepath.Join(initBackupDir, name)
+	copyPath := filepath.Join(projectPath, copyName)
+	if err := os.MkdirAll(filepath.Dir(copyPath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create %s: %v", initBackupDir, err)
+	}
+	if err := fsutil.WriteFile(copyPath, original, 0644); err != nil {
+		return fmt.Errorf("failed to keep a copy of %s: %v", name, err)
+	}
+
+	return updateInitChanges(projectPath, func(changes *config.InitChanges) {
+		changes.ModifiedFiles = append(changes.ModifiedFiles, config.ModifiedFile{
+			Path:     filepath.ToSlash(name),
+			Original: filepath.ToSlash(copyName),
+			SHA256:   digest(modified),
+		})
+	})
+}
+
+// recordInstalledPackage records a package installed by 'env init'
+func recordInstalledPackage(projectPath, pkg string) error {
+	return updateInitChanges(projectPath, func(changes *config.InitChanges) {
+		changes.InstalledPackages = append(changes.InstalledPackages, pkg)
+	})
+}
+
+// updateInitChanges saves a change made by 'env init' in the project config
+func updateInitChanges(projectPath string, updateFn func(changes *config.InitChanges)) error {
+	err := config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+		if cfg.InitChanges == nil {
+			cfg.InitChanges = &config.InitChanges{}
+		}
+		updateFn(cfg.InitChanges)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record the changes of env init: %v", err)
+	}
+	return nil
+}
+
+// digest returns the hex encoded SHA-256 digest of data
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// packageDependsOn reports whether package.json lists pkg as a dependency
+func packageDependsOn(projectPath, pkg string) bool {
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if err != nil {
+		return false
+	}
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false
+	}
+	_, dependency := manifest.Dependencies[pkg]
+	_, devDependency := manifest.DevDependencies[pkg]
+	return dependency || devDependency
+}
+
+// uninstallPackage removes a package installed by 'env init'
+func uninstallPackage(projectPath, pkg string) error {
+	cmd := exec.Command("npm", "uninstall", pkg)
+	cmd.Dir = projectPath
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	fmt.Printf("Uninstalling %s...\n", pkg)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to uninstall %s: %v", pkg, err)
+	}
+	return nil
+}
+
+// DestroyStep is a single change made by 'env destroy'
+type DestroyStep struct {
+	Description string
+	apply       func() error
+}
+
+// DestroyPlan lists the changes undoing 'env init', together with notes on
+// what is left in place
+type DestroyPlan struct {
+	Steps []DestroyStep
+	Notes []string
+}
+
+// PlanDestroy works out how to undo 'env init': deleting .env and the
+// generated types, restoring or cleaning up the files it modified and
+// uninstalling the packages it installed
+func PlanDestroy(projectPath, projectType string) (*DestroyPlan, error) {
+	cfg, err := config.LoadConfig(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	plan := &DestroyPlan{}
+
+	envFilePath := filepath.Join(projectPath, ".env")
+	if _, err := os.Stat(envFilePath); err == nil {
+		plan.Steps = append(plan.Steps, DestroyStep{
+			Description: "Delete .env (a snapshot is kept, see 'env history')",
+			apply: func() error {
+				if _, err := CreateSnapshot(projectPath, "destroy", []string{".env"}); err != nil {
+					return err
+				}
+				return DeleteFile(envFilePath)
+			},
+		})
+	}
+
+	if projectType == "bare" || projectType == "expo" {
+		typesPath := filepath.Join(projectPath, TypesFileName(projectType))
+		if _, err := os.Stat(typesPath); err == nil {
+			plan.Steps = append(plan.Steps, DestroyStep{
+				Description: "Delete " + filepath.ToSlash(TypesFileName(projectType)),
+				apply:       func() error { return DeleteFile(typesPath) },
+			})
+		}
+	}
+
+	if cfg.InitChanges != nil {
+		for _, file := range cfg.InitChanges.ModifiedFiles {
+			if step, ok := planFileRestore(projectPath, file); ok {
+				plan.Steps = append(plan.Steps, step)
+			}
+		}
+		for _, pkg := range cfg.InitChanges.InstalledPackages {
+			if !packageDependsOn(projectPath, pkg) {
+				continue
+			}
+			pkg := pkg
+			plan.Steps = append(plan.Steps, DestroyStep{
+				Description: fmt.Sprintf("Uninstall %s with npm", pkg),
+				apply:       func() error { return uninstallPackage(projectPath, pkg) },
+			})
+		}
+	} else if projectType == "bare" {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("No record of the changes made by 'env init', so the %s plugin and package are left in place.", DotenvPackage))
+	}
+
+	plan.Notes = append(plan.Notes, ".gitignore keeps its env file patterns, as other env files may remain.")
+
+	plan.Steps = append(plan.Steps, DestroyStep{
+		Description: "Mark the environment as uninitialized in " + config.ConfigFileName,
+		apply: func() error {
+			err := config.UpdateConfig(projectPath, func(cfg *config.ProjectConfig) {
+				cfg.EnvInitialized = false
+				cfg.ActiveEnv = ""
+				cfg.InitChanges = nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update project config: %v", err)
+			}
+			if err := os.RemoveAll(filepath.Join(projectPath, initBackupDir)); err != nil {
+				return fmt.Errorf("failed to delete %s: %v", initBackupDir, err)
+			}
+			return nil
+		},
+	})
+	return plan, nil
+}
+
+// planFileRestore returns the step undoing the change 'env init' made to a
+// file: the original is restored when the file was not edited since, otherwise
+// only the react-native-dotenv plugin is removed from it
+func planFileRestore(projectPath string, file config.ModifiedFile) (DestroyStep, bool) {
+	path := filepath.Join(projectPath, filepath.FromSlash(file.Path))
+	info, err := os.Stat(path)
+	if err != nil {
+		return DestroyStep{}, false
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return DestroyStep{}, false
+	}
+
+	original, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(file.Original)))
+	if err == nil && digest(current) == file.SHA256 {
+		return DestroyStep{
+			Description: fmt.Sprintf("Restore %s to its content before 'env init'", file.Path),
+			apply: func() error {
+				if err := fsutil.WriteFile(path, original, info.Mode().Perm()); err != nil {
+					return fmt.Errorf("failed to restore %s: %v", file.Path, err)
+				}
+				fmt.Printf("Restored %s\n", file.Path)
+				return nil
+			},
+		}, true
+	}
+
+	babel, err := parseBabelConfig(filepath.Base(file.Path), string(current))
+	if err != nil {
+		return DestroyStep{}, false
+	}
+	updated, removed, err := babel.removePlugin(DotenvPackage)
+	if err != nil || !removed {
+		return DestroyStep{}, false
+	}
+	return DestroyStep{
+		Description: fmt.Sprintf("Remove the %s plugin from %s", DotenvPackage, file.Path),
+		apply: func() error {
+			if err := fsutil.WriteFile(path, []byte(updated), info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to update %s: %v", file.Path, err)
+			}
+			fmt.Printf("Removed the %s plugin from %s\n", DotenvPackage, file.Path)
+			return nil
+		},
+	}, true
+}
+
+// Apply runs the steps of the plan in order
+func (p *DestroyPlan) Apply() error {
+	for _, step := range p.Steps {
+		if err := step.apply(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+//go:build unix
+
+package dotenv
+
+import (
+	"mirorim-cli/internal/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	initBabelConfig = "module.exports = {\n  presets: ['module:@react-native/babel-preset'],\n};\n"
+	initPackageJSON = "{\n  \"name\": \"app\"\n}\n"
+)
+
+// fakeNpm puts an npm on the PATH that adds and removes react-native-dotenv
+// in package.json, like npm install --save-dev and npm uninstall would
+func fakeNpm(t *testing.T) {
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+install) printf '{\n  "name": "app",\n  "devDependencies": { "react-native-dotenv": "^3.4.11" }\n}\n' > package.json ;;
+uninstall) printf '{\n  "name": "app"\n}\n' > package.json ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "npm"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// newInitProject creates a bare project that 'env init' did not run in yet
+func newInitProject(t *testing.T) string {
+	setTestHome(t)
+	fakeNpm(t)
+	project := t.TempDir()
+	for name, content := range map[string]string{"babel.config.js": initBabelConfig, "package.json": initPackageJSON} {
+		if err := os.WriteFile(filepath.Join(project, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.SaveConfig(project, &config.ProjectConfig{ProjectType: "bare"}); err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+// readProjectFile returns the content of a project file, or "" when it is missing
+func readProjectFile(t *testing.T, project, name string) string {
+	data, err := os.ReadFile(filepath.Join(project, name))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// destroyProject plans and applies 'env destroy', returning the step descriptions
+func destroyProject(t *testing.T, project string) []string {
+	plan, err := PlanDestroy(project, "bare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []string
+	for _, step := range plan.Steps {
+		steps = append(steps, step.Description)
+	}
+	if err := plan.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	return steps
+}
+
+func TestInitChangesAreUndone(t *testing.T) {
+	project := newInitProject(t)
+	if err := CreateEnvFiles(project, "bare"); err != nil {
+		t.Fatal(err)
+	}
+
+	// env init records what it changed
+	if babel := readProjectFile(t, project, "babel.config.js"); !strings.Contains(babel, "'module:react-native-dotenv'") {
+		t.Fatalf("babel.config.js does not use the plugin:\n%s", babel)
+	}
+	cfg, err := config.LoadConfig(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.InitChanges == nil {
+		t.Fatal("no changes recorded")
+	}
+	if !reflect.DeepEqual(cfg.InitChanges.InstalledPackages, []string{DotenvPackage}) {
+		t.Errorf("installed packages = %v, want %s", cfg.InitChanges.InstalledPackages, DotenvPackage)
+	}
+	if len(cfg.InitChanges.ModifiedFiles) != 1 || cfg.InitChanges.ModifiedFiles[0].Path != "babel.config.js" {
+		t.Fatalf("modified files = %+v, want babel.config.js", cfg.InitChanges.ModifiedFiles)
+	}
+	if original := readProjectFile(t, project, cfg.InitChanges.ModifiedFiles[0].Original); original != initBabelConfig {
+		t.Errorf("kept copy of babel.config.js = %q, want the original", original)
+	}
+
+	steps := destroyProject(t, project)
+	want := []string{
+		"Delete .env (a snapshot is kept, see 'env history')",
+		"Delete env.d.ts",
+		"Restore babel.config.js to its content before 'env init'",
+		"Uninstall react-native-dotenv with npm",
+		"Mark the environment as uninitialized in " + config.ConfigFileName,
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("destroy steps = %q, want %q", steps, want)
+	}
+
+	// env destroy undoes them
+	if babel := readProjectFile(t, project, "babel.config.js"); babel != initBabelConfig {
+		t.Errorf("babel.config.js = %q, want the original", babel)
+	}
+	if pkg := readProjectFile(t, project, "package.json"); pkg != initPackageJSON {
+		t.Errorf("package.json = %q, want the original", pkg)
+	}
+	for _, name := range []string{".env", EnvDTSFileName, initBackupDir} {
+		if _, err := os.Stat(filepath.Join(project, name)); !os.IsNotExist(err) {
+			t.Errorf("%s is left behind", name)
+		}
+	}
+	cfg, err = config.LoadConfig(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.EnvInitialized || cfg.InitChanges != nil {
+		t.Errorf("config = %+v, want it uninitialized without changes", cfg)
+	}
+}
+
+func TestInitChangesKeepLaterEdits(t *testing.T) {
+	project := newInitProject(t)
+	// A dependency installed before env init is not uninstalled
+	err := os.WriteFile(filepath.Join(project, "package.json"), []byte(`{"devDependencies": {"react-native-dotenv": "^3.0.0"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateEnvFiles(project, "bare"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Editing babel.config.js after env init only removes the plugin
+	babel := readProjectFile(t, project, "babel.config.js")
+	edited := strings.Replace(babel, "module.exports = {", "module.exports = {\n  comments: false,", 1)
+	if err := os.WriteFile(filepath.Join(project, "babel.config.js"), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := destroyProject(t, project)
+	for _, step := range steps {
+		if strings.HasPrefix(step, "Uninstall") {
+			t.Errorf("destroy uninstalls a package env init did not install: %q", step)
+		}
+	}
+	if len(steps) < 3 || steps[2] != "Remove the react-native-dotenv plugin from babel.config.js" {
+		t.Errorf("destroy steps = %q, want the plugin removed from babel.config.js", steps)
+	}
+	want := "module.exports = {\n  comments: false,\n  presets: ['module:@react-native/babel-preset'],\n  plugins: [],\n};\n"
+	if got := readProjectFile(t, project, "babel.config.js"); got != want {
+		t.Errorf("babel.config.js = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package fsutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileLock is an advisory lock guarding the writes to a file. Locks are kept
+// outside the project, in the user cache directory, so they never show up
+// next to the files they guard.
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// Lock waits for and acquires the lock of path. Other mirorim-cli processes
+// writing the same file wait until Unlock is called.
+func Lock(path string) (*FileLock, error) {
+	target, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := lockDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(target))
+	lockPath := filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock")
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock for %s: %v", filepath.Base(path), err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %v", filepath.Base(path), err)
+	}
+	return &FileLock{path: target, file: file}, nil
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	defer l.file.Close()
+	return unlockFile(l.file)
+}
+
+// WriteFile atomically replaces the locked file with data: the data is written
+// and synced to a temporary file in the same directory, which is then renamed
+// over the file. The mode of an existing file is kept, new files get perm.
+func (l *FileLock) WriteFile(data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(l.path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir, name := filepath.Split(l.path)
+	tmp, err := os.CreateTemp(dir, "."+name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %v", name, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %v", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %v", name, err)
+	}
+
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %v", name, err)
+	}
+	// Persist the rename itself
+	return syncDir(dir)
+}
+
+// WriteFile atomically replaces the file at path with data while holding its
+// lock. It is a safe replacement for os.WriteFile.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	lock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return lock.WriteFile(data, perm)
+}
+
+// resolvePath returns the absolute path of a file, following a symlink so the
+// link itself is not replaced by a regular file
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %v", path, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// lockDir returns the directory holding the lock files
+func lockDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	dir := filepath.Join(base, "mirorim-cli", "locks")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create lock directory: %v", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,20 @@
+//go:build !unix && !windows
+
+package fsutil
+
+import "os"
+
+// lockFile is a no-op on platforms without file locking
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without file locking
+func unlockFile(file *os.File) error {
+	return nil
+}
+
+// syncDir is a no-op on platforms without directory sync
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"fmt"
+	"mirorim-cli/internal/dotenv"
+	"mirorim-cli/internal/fsutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// importPattern finds the import statements at the top of a module
+var importPattern = regexp.MustCompile(`(?ms)^import\b(?:[^'"]*?from\s*)?['"][^'"\n]+['"];?`)
+
+// prologuePattern finds a shebang or a directive such as 'use strict' at the
+// start of what is left of a module, after blank lines and comments
+var prologuePattern = regexp.MustCompile(`^(?:\s|//[^\n]*|/\*[\s\S]*?\*/)*(?:#![^\n]*|'use [^'\n]*';?|"use [^"\n]*";?)[ \t]*(?:\n|$)`)
+
+// CanMove reports whether a finding is a string literal in a JavaScript or
+// TypeScript source, which MoveToEnv can replace with an env reference
+func CanMove(projectPath string, finding Finding) bool {
+	_, _, err := literalSpan(projectPath, finding)
+	return err == nil
+}
+
+// MoveToEnv stores the secret of a finding under key in the env file at envPath and
+// replaces the string literal in the source with a reference to the
+// variable: process.env in Expo projects, an import from the react-native-dotenv module in bare projects.
+// The env file is saved before the source is rewritten, so the secret is
+// never lost.
+func MoveToEnv(projectPath string, finding Finding, key, envPath, projectType string) error {
+	content, span, err := literalSpan(projectPath, finding)
+	if err != nil {
+		return err
+	}
+
+	// Keys are stored upper-cased, the reference must match
+	key = strings.ToUpper(key)
+	err = dotenv.UpdateEnvFile(envPath, func(envFile *dotenv.DotenvFile) error {
+		envFile.AddOrUpdateKey(key, finding.Secret)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var updated string
+	if projectType == "expo" {
+		updated = content[:span[0]] + "process.env." + key + content[span[1]:]
+	} else {
+		name := key
+		if regexp.MustCompile(`\b` + regexp.QuoteMeta(key) + `\b`).MatchString(content) {
+			// The key is already used as an identifier, import it under an alias
+			name = "ENV_" + key
+			if strings.Contains(content, name) {
+				return fmt.Errorf("%s and %s are already used in %s", key, name, finding.File)
+			}
+		}
+		updated = addEnvImport(content[:span[0]]+name+content[span[1]:], key, name, dotenv.ModuleName(projectPath))
+	}
+
+	path := filepath.Join(projectPath, filepath.FromSlash(finding.File))
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", finding.File, err)
+	}
+	err = fsutil.WriteFile(path, []byte(updated), info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", finding.File, err)
+	}
+	return nil
+}
+
+// literalSpan returns the content of the file of a finding and the byte range
+// of the quoted string literal holding its secret
+func literalSpan(projectPath string, finding Finding) (string, [2]int, error) {
+	switch filepath.Ext(finding.File) {
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+	default:
+		return "", [2]int{}, fmt.Errorf("%s is not a JavaScript or TypeScript source", finding.File)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, filepath.FromSlash(finding.File)))
+	if err != nil {
+		return "", [2]int{}, fmt.Errorf("failed to read %s: %v", finding.File, err)
+	}
+	content := string(data)
+
+	// Offset of the secret in the file
+	offset := 0
+	for line := 1; line < finding.Line; line++ {
+		next := strings.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return "", [2]int{}, fmt.Errorf("%s changed since it was scanned", finding.File)
+		}
+		offset += next + 1
+	}
+	start := offset + finding.Column - 1
+	end := start + len(finding.Secret)
+	if end > len(content) || content[start:end] != finding.Secret {
+		return "", [2]int{}, fmt.Errorf("%s changed since it was scanned", finding.File)
+	}
+
+	// The secret must be the whole literal, quotes included
+	if start == 0 || end >= len(content) || !strings.ContainsRune("'\"`", rune(content[start-1])) || content[start-1] != content[end] {
+		return "", [2]int{}, fmt.Errorf("the secret at %s:%d is not a whole string literal", finding.File, finding.Line)
+	}
+	return content, [2]int{start - 1, end + 1}, nil
+}
+
+// addEnvImport imports key from the env module moduleName as name, extending
+// an existing import from the module or adding one after the last import of the file
+func addEnvImport(content, key, name, moduleName string) string {
+	specifier := key
+	if name != key {
+		specifier = key + " as " + name
+	}
+
+	envImportPattern := regexp.MustCompile(`(?s)import\s*\{([^}]*)\}\s*from\s*['"]` + regexp.QuoteMeta(moduleName) + `['"]`)
+	if m := envImportPattern.FindStringSubmatchIndex(content); m != nil {
+		names := strings.TrimRight(content[m[2]:m[3]], " \t\n,")
+		return content[:m[2]] + names + ", " + specifier + " " + content[m[3]:]
+	}
+
+	statement := fmt.Sprintf("import { %s } from '%s';", specifier, moduleName)
+	imports := importPattern.FindAllStringIndex(content, -1)
+	if len(imports) == 0 {
+		// The shebang and the directives must stay first
+		end := prologueEnd(content)
+		if end > 0 && content[end-1] != '\n' {
+			return content + "\n" + statement + "\n"
+		}
+		return content[:end] + statement + "\n" + content[end:]
+	}
+	last := imports[len(imports)-1][1]
+	return content[:last] + "\n" + statement + content[last:]
+}
+
+// prologueEnd returns the offset of the line after the shebang and the
+// directive prologue of a module, 0 when it has none
+func prologueEnd(content string) int {
+	end := 0
+	for {
+		m := prologuePattern.FindStringIndex(content[end:])
+		if m == nil {
+			break
+		}
+		end += m[1]
+	}
+	return end
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import "testing"
+
+func TestAddEnvImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"no imports",
+			"const a = API_KEY;\n",
+			"import { API_KEY } from '@env';\nconst a = API_KEY;\n",
+		},
+		{
+			"after the last import",
+			"import React from 'react';\nimport { View } from 'react-native';\n\nconst a = API_KEY;\n",
+			"import React from 'react';\nimport { View } from 'react-native';\nimport { API_KEY } from '@env';\n\nconst a = API_KEY;\n",
+		},
+		{
+			"extends the env import",
+			"import { API_URL } from '@env';\nconst a = API_KEY;\n",
+			"import { API_URL, API_KEY } from '@env';\nconst a = API_KEY;\n",
+		},
+		{
+			"after a shebang",
+			"#!/usr/bin/env node\nconst a = API_KEY;\n",
+			"#!/usr/bin/env node\nimport { API_KEY } from '@env';\nconst a = API_KEY;\n",
+		},
+		{
+			"after directives",
+			"// Client code\n'use client';\n\"use strict\"\nconst a = API_KEY;\n",
+			"// Client code\n'use client';\n\"use strict\"\nimport { API_KEY } from '@env';\nconst a = API_KEY;\n",
+		},
+		{
+			"directive without newline",
+			"'use strict';",
+			"'use strict';\nimport { API_KEY } from '@env';\n",
+		},
+		{
+			"string that is not a directive",
+			"'use strict' + x;\n",
+			"import { API_KEY } from '@env';\n'use strict' + x;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addEnvImport(tt.content, "API_KEY", "API_KEY", "@env"); got != tt.want {
+				t.Errorf("addEnvImport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddEnvImportAlias(t *testing.T) {
+	got := addEnvImport("const a = ENV_API_KEY;\n", "API_KEY", "ENV_API_KEY", "@vars")
+	want := "import { API_KEY as ENV_API_KEY } from '@vars';\nconst a = ENV_API_KEY;\n"
+	if got != want {
+		t.Errorf("addEnvImport() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,242 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"math"
+	"mirorim-cli/internal/dotenv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ignoreMarker silences the findings of a line, for known false positives
+const ignoreMarker = "mirorim-cli:ignore-secret"
+
+// maxFileSize skips large files, which are bundles or assets rather than sources
+const maxFileSize = 1 << 20
+
+// Rule detects one kind of secret. When the pattern has a capture group, the
+// first group is the secret itself, otherwise the whole match is.
+type Rule struct {
+	Name        string
+	Description string
+	Pattern     *regexp.Regexp
+	// MinEntropy is the Shannon entropy the secret must reach, 0 to skip the check
+	MinEntropy float64
+}
+
+// Rules is the rule set used by Scan, from the most to the least specific
+var Rules = []Rule{
+	{
+		Name:        "private-key",
+		Description: "Private key block",
+		Pattern:     regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		Name:        "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+	},
+	{
+		Name:        "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws.{0,20}secret.{0,20}['"` + "`" + `]([A-Za-z0-9/+=]{40})['"` + "`" + `]`),
+		MinEntropy:  3.5,
+	},
+	{
+		Name:        "google-api-key",
+		Description: "Google API key",
+		Pattern:     regexp.MustCompile(`\b(AIza[0-9A-Za-z_\-]{35})`),
+	},
+	{
+		Name:        "stripe-secret-key",
+		Description: "Stripe secret or restricted key",
+		Pattern:     regexp.MustCompile(`\b((?:sk|rk)_(?:live|test)_[0-9A-Za-z]{16,})\b`),
+	},
+	{
+		Name:        "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,})\b`),
+	},
+	{
+		Name:        "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\b(xox[abposr]-[0-9A-Za-z-]{10,})\b`),
+	},
+	{
+		Name:        "jwt",
+		Description: "JSON Web Token",
+		Pattern:     regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,})`),
+	},
+	{
+		Name:        "secret-assignment",
+		Description: "Secret-looking value assigned to a key, token or password",
+		Pattern:     regexp.MustCompile(`(?i)(?:api_?key|secret|token|passw(?:or)?d|auth|credential)[A-Za-z0-9_]*['"]?\s*[:=]\s*['"` + "`" + `]([^'"` + "`" + `\s]{12,})['"` + "`" + `]`),
+		MinEntropy:  3.0,
+	},
+	{
+		Name:        "high-entropy-string",
+		Description: "High entropy string literal",
+		Pattern:     regexp.MustCompile(`['"` + "`" + `]([A-Za-z0-9+/=_\-]{24,})['"` + "`" + `]`),
+		MinEntropy:  4.5,
+	},
+}
+
+// Finding is a possible secret found in a project file
+type Finding struct {
+	Rule   string `json:"rule"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Secret string `json:"-"`
+	// Name is the identifier the secret is assigned to, when there is one
+	Name string `json:"name,omitempty"`
+}
+
+// skippedFiles hold hashes or secrets on purpose and are not scanned
+var skippedFiles = map[string]bool{
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Podfile.lock":      true,
+	"Gemfile.lock":      true,
+}
+
+// assignedNamePattern finds the identifier a literal is assigned to on its line
+var assignedNamePattern = regexp.MustCompile(`([A-Za-z_$][\w$]*)['"]?\s*[:=]\s*$`)
+
+// Scan walks the project and returns the possible secrets in its files,
+// skipping dependencies, native build output, env files and binary files
+func Scan(projectPath string) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.WalkDir(projectPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != projectPath && dotenv.SkippedDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skipFile(entry) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(projectPath, path)
+		findings = append(findings, scanContent(filepath.ToSlash(rel), string(data))...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %v", err)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// skipFile reports whether a file is not worth scanning
+func skipFile(entry fs.DirEntry) bool {
+	name := entry.Name()
+	if skippedFiles[name] || name == ".env" || strings.HasPrefix(name, ".env.") {
+		return true
+	}
+	info, err := entry.Info()
+	return err != nil || info.Size() > maxFileSize || !info.Mode().IsRegular()
+}
+
+// scanContent applies the rules to every line of a file. A secret is only
+// reported once, by the first rule that matches it.
+func scanContent(file, content string) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, ignoreMarker) {
+			continue
+		}
+
+		var spans [][2]int
+		for _, rule := range Rules {
+			for _, m := range rule.Pattern.FindAllStringSubmatchIndex(line, -1) {
+				start, end := m[0], m[1]
+				if len(m) > 2 && m[2] >= 0 {
+					start, end = m[2], m[3]
+				}
+				if overlaps(spans, start, end) {
+					continue
+				}
+				secret := line[start:end]
+				if rule.MinEntropy > 0 && (entropy(secret) < rule.MinEntropy || !mixedCharacters(secret)) {
+					continue
+				}
+
+				spans = append(spans, [2]int{start, end})
+				finding := Finding{Rule: rule.Name, File: file, Line: i + 1, Column: start + 1, Secret: secret}
+				if quoteStart := start - 1; quoteStart >= 0 && strings.ContainsRune("'\"`", rune(line[quoteStart])) {
+					if name := assignedNamePattern.FindStringSubmatch(line[:quoteStart]); name != nil {
+						finding.Name = name[1]
+					}
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings
+}
+
+// overlaps reports whether [start, end) overlaps one of the spans
+func overlaps(spans [][2]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var result float64
+	length := float64(len([]rune(s)))
+	for _, count := range counts {
+		p := float64(count) / length
+		result -= p * math.Log2(p)
+	}
+	return result
+}
+
+// mixedCharacters reports whether s has both letters and digits, which rules
+// out identifiers, words and paths that happen to have a high entropy
+func mixedCharacters(s string) bool {
+	return strings.ContainsAny(s, "0123456789") && strings.ContainsAny(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// Describe returns the description of a rule
+func Describe(rule string) string {
+	for _, r := range Rules {
+		if r.Name == rule {
+			return r.Description
+		}
+	}
+	return rule
+}
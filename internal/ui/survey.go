@@ -3,8 +3,13 @@ package ui
 import (
 	"fmt"
 	"mirorim-cli/internal/utils"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"golang.org/x/term"
 )
 
 // PromptProjectDetails prompts the user to select the project type and input a project name.
@@ -59,7 +64,7 @@ func PromptEnvKeyValue(operation string) (string, string, error) {
 	keyPrompt := &survey.Input{
 		Message: "Enter the environment variable key:",
 	}
-	err := survey.AskOne(keyPrompt, &key)
+	err := survey.AskOne(keyPrompt, &key, survey.WithValidator(utils.ValidateEnvKey))
 	if err != nil {
 		return "", "", err
 	}
@@ -97,3 +102,64 @@ func PromptNewValue() (string, error) {
 	err := survey.AskOne(prompt, &value)
 	return value, err
 }
+
+// PromptEnvKey prompts for the name of a new environment variable, suggesting defaultKey
+func PromptEnvKey(message, defaultKey string) (string, error) {
+	var key string
+	prompt := &survey.Input{
+		Message: message,
+		Default: defaultKey,
+	}
+	err := survey.AskOne(prompt, &key, survey.WithValidator(utils.ValidateEnvKey))
+	return key, err
+}
+
+// PromptConfirm asks a yes/no question and defaults to no
+func PromptConfirm(message string) (bool, error) {
+	var confirmed bool
+	prompt := &survey.Confirm{
+		Message: message,
+	}
+	err := survey.AskOne(prompt, &confirmed)
+	return confirmed, err
+}
+
+// PromptPassword prompts the user for a secret without echoing it
+func PromptPassword(message string) (string, error) {
+	var password string
+	prompt := &survey.Password{
+		Message: message,
+	}
+	err := survey.AskOne(prompt, &password, survey.WithValidator(survey.Required))
+	return password, err
+}
+
+// IsInteractive reports whether stdin is attached to a terminal, so prompts can be shown
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// OpenEditor opens a file in the editor named by $VISUAL or $EDITOR and waits
+// for it to close. The variable may include arguments, like "code --wait".
+func OpenEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %s: %v", args[0], err)
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 )
 
 // ValidateProjectName validates the project name to ensure it follows naming conventions.
@@ -19,3 +21,38 @@ func ValidateProjectName(val interface{}) error {
 	}
 	return nil
 }
+
+// ValidateEnvName validates an environment name such as "staging" used in .env.<name> file names.
+func ValidateEnvName(val interface{}) error {
+	name, ok := val.(string)
+	if !ok {
+		return errors.New("invalid type of input")
+	}
+
+	match, _ := regexp.MatchString(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`, name)
+	if !match {
+		return errors.New("environment name must start with a letter or digit and contain only alphanumeric characters, dashes, or underscores")
+	}
+
+	// These suffixes name the example, encrypted and temporary files, not environments
+	for _, reserved := range []string{"example", "enc", "bak", "tmp", "lock"} {
+		if strings.EqualFold(name, reserved) {
+			return fmt.Errorf("%q is reserved for .env.%s files and cannot name an environment", name, reserved)
+		}
+	}
+	return nil
+}
+
+// ValidateEnvKey validates an environment variable key such as "API_URL".
+func ValidateEnvKey(val interface{}) error {
+	key, ok := val.(string)
+	if !ok {
+		return errors.New("invalid type of input")
+	}
+
+	match, _ := regexp.MatchString(`^[a-zA-Z_][a-zA-Z0-9_.-]*$`, key)
+	if !match {
+		return fmt.Errorf("invalid key %q: keys must start with a letter or underscore and contain only alphanumeric characters, underscores, dots, or dashes", key)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+func TestValidateEnvName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"staging", true},
+		{"prod-eu_2", true},
+		{"2024", true},
+		{"", false},
+		{"-staging", false},
+		{"../x", false},
+		{"/../../x", false},
+		{"a.b", false},
+		{"example", false},
+		{"Enc", false},
+		{"lock", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateEnvName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateEnvName(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}